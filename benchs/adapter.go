@@ -0,0 +1,72 @@
+package benchs
+
+import (
+	"context"
+	"errors"
+)
+
+// Adapter implements the primitive operations of one ORM on the models
+// table. The workloads drive every adapter the same way, so they all run
+// the same queries. The operations are called from all RunParallel
+// workers at once and must be safe for concurrent use. An ORM without a
+// context aware API may ignore ctx.
+type Adapter interface {
+	// Connect opens the connection pool to ORM_SOURCE, limited by
+	// ORM_MAX_IDLE and ORM_MAX_CONN.
+	Connect() error
+	// InsertOne inserts m and sets m.Id to the generated id if the ORM
+	// returns it with the insert, setup reads it from the table otherwise.
+	InsertOne(ctx context.Context, m *Model) error
+	// InsertMany inserts ms with one statement, their ids needn't be set.
+	InsertMany(ctx context.Context, ms []*Model) error
+	// UpdateByPK writes all columns of m to the row with m.Id.
+	UpdateByPK(ctx context.Context, m *Model) error
+	// GetByPK reads the row with id into m.
+	GetByPK(ctx context.Context, id int, m *Model) error
+	// FindRange reads at most limit rows with an id above minID ordered by
+	// id and returns how many it read.
+	FindRange(ctx context.Context, minID, limit int) (int, error)
+}
+
+// Preparer is implemented by an adapter that prepares statements, it's
+// called before every benchmark after the models table was recreated.
+type Preparer interface {
+	Prepare(ctx context.Context) error
+}
+
+// NotSupported is returned by an adapter for an operation its ORM can't
+// do, the benchmark is skipped with it as the reason.
+type NotSupported string
+
+func (e NotSupported) Error() string {
+	return string(e)
+}
+
+// NewAdapterSuite registers a suite named name running all workloads
+// against a, it connects with a.Connect.
+func NewAdapterSuite(name string, a Adapter) *Suite {
+	st := NewSuite(name)
+	st.BenchsF = func() {
+		for _, w := range workloads {
+			w := w
+			st.AddBenchmark(w.Name, w.N*ORM_MULTI, w.L, func(b *B) {
+				w.Run(b, a)
+			})
+		}
+	}
+	st.InitF = a.Connect
+	return st
+}
+
+// check stops b if err is set, an operation the adapter doesn't support
+// skips it.
+func check(b *B, err error) {
+	if err == nil {
+		return
+	}
+	var ns NotSupported
+	if errors.As(err, &ns) {
+		b.Skip(string(ns))
+	}
+	b.Fatal(err)
+}
@@ -0,0 +1,100 @@
+package benchs
+
+import (
+	"database/sql"
+	"flag"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var (
+	testDialect     = flag.String("dialect", "mysql", "database dialect of BenchmarkORM")
+	testSource      = flag.String("source", "", "dsn source of BenchmarkORM, defaults to a local server of the dialect")
+	testMaxIdle     = flag.Int("max_idle", 200, "max idle conns of BenchmarkORM")
+	testMaxConn     = flag.Int("max_conn", 200, "max open conns of BenchmarkORM")
+	testConcurrency = flag.Int("concurrency", 1, "goroutines sharing b.N in BenchmarkORM")
+)
+
+// BenchmarkORM runs the benchmarks of every registered suite as
+// sub-benchmarks named ORM/Operation with the same setup and operations
+// as the run command, so the go test tools work on them, e.g.
+//
+//	go test ./benchs -run '^$' -bench 'ORM/(raw|gorm)/Read' -benchmem -dialect postgres
+//
+// testing picks b.N, the time and allocations of the setup are excluded
+// like in the run command. It's skipped when the database can't be
+// reached.
+func BenchmarkORM(tb *testing.B) {
+	if err := UseDialect(*testDialect); err != nil {
+		tb.Fatal(err)
+	}
+	ORM_SOURCE = *testSource
+	if ORM_SOURCE == "" {
+		ORM_SOURCE = dialect.DefaultSource
+	}
+	ORM_MAX_IDLE = *testMaxIdle
+	ORM_MAX_CONN = *testMaxConn
+	ORM_CONCURRENCY = *testConcurrency
+	if ORM_MULTI == 0 {
+		ORM_MULTI = 1
+	}
+	if err := pingDB(); err != nil {
+		tb.Skipf("no database: %v", err)
+	}
+
+	for _, name := range BrandNames {
+		s := benchmarks[name]
+		s.prepare()
+		var connect sync.Once
+		var connectErr error
+		for _, b := range s.benchs {
+			b := b
+			tb.Run(name+"/"+b.Name, func(tb *testing.B) {
+				if !s.supports(dialect) {
+					tb.Skipf("only runs on %s", strings.Join(s.Dialects, ", "))
+				}
+				connect.Do(func() { connectErr = s.connect() })
+			if connectErr != nil {
+				tb.Fatalf("connect: %v", connectErr)
+			}
+				runTB(tb, b)
+			})
+		}
+	}
+}
+
+// pingDB checks the database initDB would connect to.
+func pingDB() error {
+	db, err := sql.Open(dialect.DriverName, ORM_SOURCE)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.Ping()
+}
+
+// runTB runs b for tb.N operations. Like the run command it runs in a
+// goroutine of its own, so FailNow and Skip don't end tb's.
+func runTB(tb *testing.B, b *B) {
+	r := &B{
+		common: common{
+			signal: make(chan interface{}, 1),
+		},
+		Name:  b.Name,
+		Brand: b.Brand,
+		N:     tb.N,
+		L:     b.L,
+		F:     b.F,
+		tb:    tb,
+	}
+	go r.launch(r.signal)
+	result := (<-r.signal).(*BenchmarkResult)
+	switch result.Status {
+	case StatusSkipped:
+		tb.Skip(result.SkippedMsg)
+	case StatusOK:
+	default:
+		tb.Fatal(result.FailedMsg)
+	}
+}
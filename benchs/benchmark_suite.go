@@ -1,19 +1,118 @@
 package benchs
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
+	"os"
 	"runtime"
 	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"text/tabwriter"
 	"time"
 )
 
+// Status tells whether a benchmark ran, was skipped or failed.
+type Status int
+
+const (
+	StatusOK Status = iota
+	StatusSkipped
+	StatusFailed
+	StatusTimeout
+	StatusInterrupted
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusOK:
+		return "ok"
+	case StatusSkipped:
+		return "skipped"
+	case StatusFailed:
+		return "failed"
+	case StatusTimeout:
+		return "timeout"
+	case StatusInterrupted:
+		return "interrupted"
+	}
+	return fmt.Sprintf("Status(%d)", int(s))
+}
+
+// MarshalText saves the status by name in the results file.
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Status) UnmarshalText(text []byte) error {
+	for st := StatusOK; st <= StatusInterrupted; st++ {
+		if st.String() == string(text) {
+			*s = st
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown status %q", text)
+}
+
 type BenchmarkResult struct {
-	N         int
-	T         time.Duration
-	MemAllocs uint64
-	MemBytes  uint64
-	FailedMsg string
+	N          int
+	T          time.Duration
+	MemAllocs  uint64
+	MemBytes   uint64
+	Status     Status
+	FailedMsg  string
+	SkippedMsg string
+	// Error is the first error reported by Error or Fatal, ErrorCount
+	// the number of errors reported in total.
+	Error      *BenchmarkError
+	ErrorCount int
+	// Workers holds one entry per RunParallel goroutine.
+	Workers []WorkerResult
+	// Per operation latencies, only set when the benchmark used RunParallel.
+	P50  time.Duration
+	P90  time.Duration
+	P99  time.Duration
+	P999 time.Duration
+	Max  time.Duration
+	// First is the slowest first operation of all workers, it includes
+	// connection setup, statement preparation and cache misses.
+	First time.Duration
+	// Warmup holds the untimed -warmup iterations run before the
+	// measurement.
+	Warmup *BenchmarkResult
+	// Overhead is the harness cost in ns/op that was subtracted from T.
+	Overhead float64 `json:",omitempty"`
+	// Load is the system load sampled before the run.
+	Load *Load `json:",omitempty"`
+	// Histogram holds the latencies the percentiles were taken from, so
+	// the samples of saved results can be merged like those of a run.
+	Histogram *Histogram `json:",omitempty"`
+}
+
+func (r *BenchmarkResult) setLatency(h *Histogram) {
+	r.Histogram = h
+	r.P50 = h.Percentile(50)
+	r.P90 = h.Percentile(90)
+	r.P99 = h.Percentile(99)
+	r.P999 = h.Percentile(99.9)
+	r.Max = h.Max()
+}
+
+// WorkerResult is the share of b.N one RunParallel goroutine executed.
+type WorkerResult struct {
+	N     int
+	T     time.Duration
+	First time.Duration
+}
+
+func (w WorkerResult) NsPerOp() int64 {
+	if w.N <= 0 {
+		return 0
+	}
+	return w.T.Nanoseconds() / int64(w.N)
 }
 
 func (r BenchmarkResult) NsPerOp() int64 {
@@ -23,6 +122,13 @@ func (r BenchmarkResult) NsPerOp() int64 {
 	return r.T.Nanoseconds() / int64(r.N)
 }
 
+func (r BenchmarkResult) nsPerOp() float64 {
+	if r.N <= 0 {
+		return 0
+	}
+	return float64(r.T.Nanoseconds()) / float64(r.N)
+}
+
 func (r BenchmarkResult) AllocsPerOp() int64 {
 	if r.N <= 0 {
 		return 0
@@ -37,9 +143,24 @@ func (r BenchmarkResult) AllocedBytesPerOp() int64 {
 	return int64(r.MemBytes) / int64(r.N)
 }
 
+// OpsPerSec is the aggregate throughput of all workers.
+func (r BenchmarkResult) OpsPerSec() float64 {
+	if r.T <= 0 {
+		return 0
+	}
+	return float64(r.N) / r.T.Seconds()
+}
+
 func (r BenchmarkResult) String() string {
-	if len(r.FailedMsg) > 0 {
-		return "    " + r.FailedMsg
+	switch r.Status {
+	case StatusFailed:
+		return "    FAIL: " + r.FailedMsg
+	case StatusTimeout:
+		return "    TIMEOUT: " + r.FailedMsg
+	case StatusInterrupted:
+		return "    INTERRUPTED: " + r.FailedMsg
+	case StatusSkipped:
+		return "    skipped: " + r.SkippedMsg
 	}
 
 	nsop := r.NsPerOp()
@@ -53,13 +174,49 @@ func (r BenchmarkResult) String() string {
 			ns = fmt.Sprintf("%9.1f ns/op", float64(r.T.Nanoseconds())/float64(r.N))
 		}
 	}
-	return fmt.Sprintf("%s%s", total, ns) + fmt.Sprintf("%8d B/op  %5d allocs/op",
+	s := fmt.Sprintf("%s%s", total, ns) + fmt.Sprintf("%8d B/op  %5d allocs/op",
 		r.AllocedBytesPerOp(), r.AllocsPerOp())
+	if len(r.Workers) > 1 {
+		s += fmt.Sprintf("  %10.1f ops/s", r.OpsPerSec())
+	}
+	return s
+}
+
+// LatencyString prints the per operation latency percentiles.
+func (r BenchmarkResult) LatencyString() string {
+	if r.Max <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("p50 %d  p90 %d  p99 %d  p99.9 %d  max %d ns",
+		r.P50.Nanoseconds(), r.P90.Nanoseconds(), r.P99.Nanoseconds(), r.P999.Nanoseconds(), r.Max.Nanoseconds())
+}
+
+// WarmupString prints the cost of the warmup iterations.
+func (r BenchmarkResult) WarmupString() string {
+	if r.Warmup == nil {
+		return ""
+	}
+	w := r.Warmup
+	return fmt.Sprintf("warmup: %d ops %5.2fs %10d ns/op %8d B/op %5d allocs/op, first op %d ns",
+		w.N, w.T.Seconds(), w.NsPerOp(), w.AllocedBytesPerOp(), w.AllocsPerOp(), w.First.Nanoseconds())
+}
+
+// WorkersString prints one line per RunParallel goroutine.
+func (r BenchmarkResult) WorkersString(indent string) (s string) {
+	if len(r.Workers) < 2 {
+		return
+	}
+	for i, w := range r.Workers {
+		s += fmt.Sprintf("%sworker %2d: %6d ops %10d ns/op\n", indent, i, w.N, w.NsPerOp())
+	}
+	return
 }
 
 type common struct {
-	mu     sync.RWMutex
-	failed bool
+	mu      sync.RWMutex
+	failed  bool
+	skipped bool
+	skipMsg string
 
 	start    time.Time
 	duration time.Duration
@@ -71,15 +228,53 @@ func (c *common) Fail() {
 	defer c.mu.Unlock()
 	c.failed = true
 }
+func (c *common) Failed() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.failed
+}
 func (c *common) FailNow() {
 	c.Fail()
 	runtime.Goexit()
 }
 
+// Skip marks the benchmark as not supported by the ORM and stops it.
+// Unlike a failure it doesn't make the run exit non-zero.
+func (c *common) Skip(args ...interface{}) {
+	c.mu.Lock()
+	c.skipped = true
+	c.skipMsg = fmt.Sprint(args...)
+	c.mu.Unlock()
+	runtime.Goexit()
+}
+func (c *common) Skipf(format string, args ...interface{}) {
+	c.Skip(fmt.Sprintf(format, args...))
+}
+func (c *common) Skipped() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.skipped
+}
+
 var benchmarkLock sync.Mutex
 
 var memStats runtime.MemStats
 
+// readMem takes an allocation snapshot. ReadMemStats stops the world, so
+// it's only called at the boundaries of the measured phase, never by the
+// timer methods.
+func readMem() (allocs, bytes uint64) {
+	runtime.ReadMemStats(&memStats)
+	return memStats.Mallocs, memStats.TotalAlloc
+}
+
+// testTimer is the part of testing.B that B drives, see bench_test.go.
+type testTimer interface {
+	StartTimer()
+	StopTimer()
+	ResetTimer()
+}
+
 type B struct {
 	common
 	Brand string
@@ -87,23 +282,119 @@ type B struct {
 	N     int
 	L     int
 	F     func(b *B)
+	// Timeout overrides -timeout for this benchmark.
+	Timeout time.Duration
 
 	timerOn bool
-
-	startAllocs uint64
-	startBytes  uint64
+	// tb is the testing.B the benchmark runs under with go test -bench,
+	// its timer follows this one.
+	tb testTimer
 
 	netAllocs uint64
 	netBytes  uint64
+	// setupAllocs and setupBytes are the allocations of wrapExecute in a
+	// benchmark not using RunParallel, they're left out of its result.
+	setupAllocs uint64
+	setupBytes  uint64
+	// measured is set once RunParallel took the allocation snapshots.
+	measured bool
+	// warned is set once warnPlainLoop printed its warning.
+	warned bool
+	// pinned is the N of each sample from ORM_PINNED.
+	pinned []int
+
+	workers []WorkerResult
+	hist    *Histogram
+	warmup  *BenchmarkResult
+	// deadline ends RunParallel loops by time instead of b.N.
+	deadline time.Time
+	// next is the iteration counter of a running RunParallel.
+	next *int64
+	ctx  context.Context
+
+	err      *BenchmarkError
+	errCount int
 
 	result *BenchmarkResult
+	// samples holds the successful results of every -count repetition.
+	samples []*BenchmarkResult
+	// outliers holds the samples dropped to be measured again, unstable
+	// is set when outliers remained after ORM_REMEASURE re-measurements.
+	outliers []*BenchmarkResult
+	unstable bool
+}
+
+// iteration returns the index of the operation in progress, -1 outside
+// of RunParallel. With more than one worker it is the latest operation
+// handed out, which is close to but not always the failing one.
+func (b *B) iteration() int {
+	if b.next == nil {
+		return -1
+	}
+	i := int(atomic.LoadInt64(b.next)) - 1
+	if i >= b.N {
+		i = b.N - 1
+	}
+	return i
+}
+
+func (b *B) recordError(err error) {
+	e := newBenchmarkError(err, b.iteration())
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.err == nil {
+		b.err = e
+	}
+	b.errCount++
+}
+
+// Error records args as the error of the benchmark and marks it failed,
+// the current operation keeps running but pb.Next ends the loop. A single
+// error argument is kept as is so its driver error code can be reported.
+func (b *B) Error(args ...interface{}) {
+	if len(args) == 1 {
+		if err, ok := args[0].(error); ok {
+			b.recordError(err)
+			b.Fail()
+			return
+		}
+	}
+	b.recordError(errors.New(fmt.Sprint(args...)))
+	b.Fail()
+}
+
+// Errorf is like Error, %w keeps the wrapped driver error.
+func (b *B) Errorf(format string, args ...interface{}) {
+	b.recordError(fmt.Errorf(format, args...))
+	b.Fail()
+}
+
+// Fatal is Error followed by FailNow.
+func (b *B) Fatal(args ...interface{}) {
+	b.Error(args...)
+	b.FailNow()
+}
+
+// Fatalf is Errorf followed by FailNow.
+func (b *B) Fatalf(format string, args ...interface{}) {
+	b.Errorf(format, args...)
+	b.FailNow()
 }
 
+// Stats summarizes all repetitions of the benchmark.
+func (b *B) Stats() Stats {
+	return Stats{Samples: b.samples}
+}
+
+// StartTimer and StopTimer only read the clock, so they are cheap enough
+// to call around setup code. Allocations are counted by RunParallel for
+// its loop only, a benchmark not using it has them counted around F
+// without those of wrapExecute.
 func (b *B) StartTimer() {
 	if !b.timerOn {
-		runtime.ReadMemStats(&memStats)
-		b.startAllocs = memStats.Mallocs
-		b.startBytes = memStats.TotalAlloc
+		if b.tb != nil {
+			b.tb.StartTimer()
+		}
 		b.start = time.Now()
 		b.timerOn = true
 	}
@@ -111,19 +402,41 @@ func (b *B) StartTimer() {
 
 func (b *B) StopTimer() {
 	if b.timerOn {
+		if b.tb != nil {
+			b.tb.StopTimer()
+		}
 		b.duration += time.Now().Sub(b.start)
-		runtime.ReadMemStats(&memStats)
-		b.netAllocs += memStats.Mallocs - b.startAllocs
-		b.netBytes += memStats.TotalAlloc - b.startBytes
 		b.timerOn = false
 	}
 }
 
+// excludeMem runs setup, in a benchmark not using RunParallel its
+// allocations are left out of the result.
+func (b *B) excludeMem(setup func()) {
+	if b.measured || b.next != nil {
+		setup()
+		return
+	}
+	allocs, bytes := readMem()
+	setup()
+	a, by := readMem()
+	b.setupAllocs += a - allocs
+	b.setupBytes += by - bytes
+}
+
+// elapsed is the measured time so far, including a running timer.
+func (b *B) elapsed() time.Duration {
+	if b.timerOn {
+		return b.duration + time.Now().Sub(b.start)
+	}
+	return b.duration
+}
+
 func (b *B) ResetTimer() {
+	if b.tb != nil {
+		b.tb.ResetTimer()
+	}
 	if b.timerOn {
-		runtime.ReadMemStats(&memStats)
-		b.startAllocs = memStats.Mallocs
-		b.startBytes = memStats.TotalAlloc
 		b.start = time.Now()
 	}
 	b.duration = 0
@@ -131,87 +444,621 @@ func (b *B) ResetTimer() {
 	b.netBytes = 0
 }
 
-func (b *B) launch() {
-	benchmarkLock.Lock()
-
+// launch runs b.F and sends the result on signal, the b.signal it was
+// started with. It may be abandoned by run on timeout, so it must not
+// touch b.result or b.samples, nor b.signal which abort replaces then.
+func (b *B) launch(signal chan<- interface{}) {
 	defer func() {
+		var result *BenchmarkResult
 		if err := recover(); err != nil {
 			b.failed = true
 			//panic(err)
-			b.result = &BenchmarkResult{FailedMsg: fmt.Sprint(err)}
+			e, ok := err.(error)
+			if !ok {
+				e = fmt.Errorf("panic: %v", err)
+			}
+			b.recordError(e)
+			result = &BenchmarkResult{Status: StatusFailed}
+		} else if b.skipped {
+			result = &BenchmarkResult{Status: StatusSkipped, SkippedMsg: b.skipMsg}
+		} else if b.failed {
+			result = &BenchmarkResult{Status: StatusFailed}
 		} else {
-			b.result = &BenchmarkResult{b.N, b.duration, b.netAllocs, b.netBytes, ""}
+			result = &BenchmarkResult{
+				N:         b.N,
+				T:         b.duration,
+				MemAllocs: b.netAllocs,
+				MemBytes:  b.netBytes,
+				Workers:   b.workers,
+				First:     firstOp(b.workers),
+				Warmup:    b.warmup,
+			}
+			if b.hist != nil && b.hist.Count() > 0 {
+				result.setLatency(b.hist)
+			}
+			if b.measured {
+				result.subtractOverhead(harnessOverhead)
+			}
+		}
+		if result.Status == StatusFailed {
+			result.Error, result.ErrorCount = b.err, b.errCount
+			if b.err != nil {
+				result.FailedMsg = b.err.String()
+				if b.errCount > 1 {
+					result.FailedMsg += fmt.Sprintf(", %d errors", b.errCount)
+				}
+			} else {
+				result.FailedMsg = "FailNow called"
+			}
 		}
 
-		b.signal <- b
-		benchmarkLock.Unlock()
+		signal <- result
 	}()
 
+	b.workers = nil
+	b.hist = nil
+	b.warmup = nil
+	b.err = nil
+	b.errCount = 0
+	b.next = nil
+	b.measured = false
+	b.setupAllocs = 0
+	b.setupBytes = 0
+	switch i := len(b.samples); {
+	case i < len(b.pinned):
+		b.N = b.pinned[i]
+	case ORM_BENCHTIME.N > 0:
+		b.N = ORM_BENCHTIME.N
+	}
+
 	runtime.GC()
+	allocs, bytes := readMem()
 	b.ResetTimer()
 	b.StartTimer()
 	b.F(b)
 	b.StopTimer()
+	if !b.measured {
+		b.addMem(allocs+b.setupAllocs, bytes+b.setupBytes)
+		b.warnPlainLoop()
+	}
+}
+
+// warnPlainLoop prints a warning, once, when -benchtime is a duration or
+// -warmup is set but b doesn't use RunParallel, which applies them: its
+// loop runs b.N times unwarmed.
+func (b *B) warnPlainLoop() {
+	if b.warned || b.failed || b.skipped {
+		return
+	}
+	var ignored []string
+	if ORM_BENCHTIME.D > 0 {
+		ignored = append(ignored, "-benchtime "+ORM_BENCHTIME.String())
+	}
+	if ORM_WARMUP.N > 0 || ORM_WARMUP.D > 0 {
+		ignored = append(ignored, "-warmup "+ORM_WARMUP.String())
+	}
+	if len(ignored) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: %s %s doesn't use RunParallel, ignoring %s, it runs %d times\n",
+			b.Brand, b.Name, strings.Join(ignored, " and "), b.N)
+		b.warned = true
+	}
+}
+
+// addMem adds the allocations since the snapshot allocs, bytes.
+func (b *B) addMem(allocs, bytes uint64) {
+	a, by := readMem()
+	b.netAllocs += a - allocs
+	b.netBytes += by - bytes
+}
+
+// PB hands out the iterations of b.N to one RunParallel goroutine.
+// Every call to Next marks the boundary between two operations, the
+// time in between is recorded as the latency of one operation.
+type PB struct {
+	b     *B
+	next  *int64
+	n     int
+	last  time.Time
+	first time.Duration
+	hist  *Histogram
+}
+
+// Next reports whether there are more iterations to execute.
+func (pb *PB) Next() bool {
+	now := time.Now()
+	if pb.n > 0 {
+		took := now.Sub(pb.last)
+		pb.hist.Record(took)
+		if pb.n == 1 {
+			pb.first = took
+		}
+	}
+	if pb.b.Failed() || pb.b.Skipped() || pb.b.Context().Err() != nil {
+		return false
+	}
+	if !pb.b.deadline.IsZero() && now.After(pb.b.deadline) {
+		return false
+	}
+	if atomic.AddInt64(pb.next, 1) > int64(pb.b.N) {
+		return false
+	}
+	pb.n++
+	pb.last = now
+	return true
+}
+
+// RunParallel runs body in ORM_CONCURRENCY goroutines sharing b.N.
+// Each goroutine should set up its own state and then iterate until
+// pb.Next returns false. FailNow inside body stops all goroutines.
+//
+// With a -benchtime duration b.N is calibrated here: body runs in rounds
+// of growing N until one round takes at least that long, so the setup
+// done by the benchmark before RunParallel happens only once.
+//
+// A -warmup runs body before any of that, its cost is kept apart from
+// the result.
+func (b *B) RunParallel(body func(pb *PB)) {
+	if ORM_WARMUP.N > 0 || ORM_WARMUP.D > 0 {
+		b.runWarmup(body)
+	}
+
+	switch {
+	case len(b.samples) < len(b.pinned):
+		// launch pinned b.N
+	case ORM_BENCHTIME.N > 0:
+		b.N = ORM_BENCHTIME.N
+	case ORM_BENCHTIME.D > 0:
+		for n := 1; ; {
+			b.N = n
+			b.ResetTimer()
+			b.runParallel(body)
+			took := b.elapsed()
+			if took >= ORM_BENCHTIME.D || n >= 1e9 {
+				return
+			}
+			n = predictN(ORM_BENCHTIME.D, took, n)
+		}
+	}
+	b.runParallel(body)
+}
+
+// runWarmup runs body for -warmup iterations or duration and records
+// what it took in b.warmup.
+func (b *B) runWarmup(body func(pb *PB)) {
+	n := b.N
+	if ORM_WARMUP.N > 0 {
+		b.N = ORM_WARMUP.N
+	} else {
+		b.N = math.MaxInt32
+		b.deadline = time.Now().Add(ORM_WARMUP.D)
+	}
+
+	b.ResetTimer()
+	b.runParallel(body)
+	b.StopTimer()
+
+	w := &BenchmarkResult{
+		T:         b.duration,
+		MemAllocs: b.netAllocs,
+		MemBytes:  b.netBytes,
+		First:     firstOp(b.workers),
+	}
+	for _, r := range b.workers {
+		w.N += r.N
+	}
+	b.warmup = w
+
+	b.N = n
+	b.deadline = time.Time{}
+	b.ResetTimer()
+	b.StartTimer()
+}
+
+// firstOp returns the slowest first operation of all workers.
+func firstOp(workers []WorkerResult) (first time.Duration) {
+	for _, w := range workers {
+		if w.First > first {
+			first = w.First
+		}
+	}
+	return
+}
+
+func (b *B) runParallel(body func(pb *PB)) {
+	workers := ORM_CONCURRENCY
+	if workers < 1 {
+		workers = 1
+	}
+
+	var (
+		next     int64
+		wg       sync.WaitGroup
+		panicMu  sync.Mutex
+		panicked interface{}
+	)
+	results := make([]WorkerResult, workers)
+
+	// histograms are allocated before the allocation snapshot so they
+	// don't count as allocations of the operation.
+	timerOn := b.timerOn
+	b.StopTimer()
+	hists := make([]*Histogram, workers)
+	for w := range hists {
+		hists[w] = NewHistogram()
+	}
+	b.next = &next
+	allocs, bytes := readMem()
+	if timerOn {
+		b.StartTimer()
+	}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			pb := &PB{b: b, next: &next, hist: hists[w]}
+			start := time.Now()
+			defer func() {
+				results[w] = WorkerResult{pb.n, time.Now().Sub(start), pb.first}
+				if err := recover(); err != nil {
+					panicMu.Lock()
+					if panicked == nil {
+						panicked = err
+					}
+					panicMu.Unlock()
+					b.Fail()
+				}
+				wg.Done()
+			}()
+			body(pb)
+		}(w)
+	}
+	wg.Wait()
+
+	b.StopTimer()
+	b.addMem(allocs, bytes)
+	b.measured = true
+	if timerOn {
+		b.StartTimer()
+	}
+
+	b.workers = results
+	b.hist = hists[0]
+	for _, h := range hists[1:] {
+		b.hist.Merge(h)
+	}
+
+	// b.next stays set on failure, launch reads it for the error report
+	if panicked != nil {
+		panic(panicked)
+	}
+	if b.Failed() || b.Skipped() {
+		runtime.Goexit()
+	}
+	b.next = nil
 }
 
+// Context is cancelled when the benchmark times out, suites pass it to
+// the ORM where it supports one so in-flight queries are aborted.
+func (b *B) Context() context.Context {
+	if b.ctx == nil {
+		return context.Background()
+	}
+	return b.ctx
+}
+
+// timeout returns how long b may run, taking the -total_timeout deadline
+// into account. Zero means no limit, negative that the deadline is over.
+func (b *B) timeout() time.Duration {
+	d := b.Timeout
+	if d == 0 {
+		d = ORM_TIMEOUT
+	}
+	if !ORM_DEADLINE.IsZero() {
+		left := time.Until(ORM_DEADLINE)
+		if left <= 0 {
+			return -1
+		}
+		if d == 0 || left < d {
+			d = left
+		}
+	}
+	return d
+}
+
+// timeoutGrace is how long run waits for a timed out benchmark to return
+// after its context was cancelled.
+const timeoutGrace = time.Second
+
 func (b *B) run() {
-	go b.launch()
-	<-b.signal
+	benchmarkLock.Lock()
+	defer benchmarkLock.Unlock()
+
+	d := b.timeout()
+	if d < 0 {
+		b.result = &BenchmarkResult{Status: StatusTimeout, FailedMsg: "not run, -total_timeout reached"}
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b.ctx = ctx
+
+	var timeout <-chan time.Time
+	if d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	load := sampleLoad()
+	warnBusy(b, load)
+
+	go b.launch(b.signal)
+
+	select {
+	case r := <-b.signal:
+		b.result = r.(*BenchmarkResult)
+		b.result.Load = load
+		if b.result.Status == StatusOK {
+			b.samples = append(b.samples, b.result)
+			b.checkOutliers(ORM_COUNT)
+		}
+	case <-timeout:
+		b.abort(cancel, StatusTimeout, fmt.Sprintf("timed out after %v", d))
+	case <-aborted:
+		b.abort(cancel, StatusInterrupted, "aborted")
+	}
+}
+
+// abort cancels the context of the running benchmark and waits a little
+// for it to return.
+func (b *B) abort(cancel context.CancelFunc, status Status, msg string) {
+	cancel()
+	b.result = &BenchmarkResult{Status: status, FailedMsg: msg}
+	select {
+	case r := <-b.signal:
+		if msg := r.(*BenchmarkResult).FailedMsg; len(msg) > 0 {
+			b.result.FailedMsg += ": " + msg
+		}
+	case <-time.After(timeoutGrace):
+		// the benchmark goroutine is stuck and abandoned, it keeps the
+		// old signal channel to itself.
+		b.result.FailedMsg += ", benchmark abandoned"
+		b.signal = make(chan interface{}, 1)
+	}
+}
+
+// remaining returns how many of count repetitions b has yet to run. A
+// benchmark that didn't succeed isn't repeated, unless it was aborted.
+func (b *B) remaining(count int) int {
+	return remaining(b.result, len(b.samples), count)
+}
+
+func remaining(last *BenchmarkResult, samples, count int) int {
+	if count < 1 {
+		count = 1
+	}
+	if last != nil && last.Status != StatusOK && last.Status != StatusInterrupted {
+		return 0
+	}
+	return count - samples
 }
 
-type suite struct {
-	Brand  string
-	InitF  func()
-	benchs []*B
-	orders []string
+// Suite is the benchmarks of one ORM. A package outside of benchs adds
+// its own with NewSuite, NewAdapterSuite or Register from an init
+// function, a main that blank imports it runs and reports them with the
+// built in ones.
+type Suite struct {
+	// Brand is the name the suite is selected by with -orm.
+	Brand string
+	// BenchsF registers the benchmarks with AddBenchmark, it must not
+	// connect to the database so the suite can be listed without one.
+	BenchsF func()
+	// InitF connects to the database before the benchmarks run, it may be
+	// nil for a suite that connects by itself. An error fails the
+	// benchmarks of the suite, the other suites still run.
+	InitF func() error
+	// Dialects limits the suite to the named dialects, empty means all.
+	Dialects []string
+	benchs   []*B
+	orders   []string
+	// skipMsg tells why the whole suite didn't run.
+	skipMsg  string
+	prepared bool
+}
+
+// find returns the registered benchmark named name.
+func (st *Suite) find(name string) *B {
+	for _, b := range st.benchs {
+		if b.Name == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// prepare registers the benchmarks of the suite once.
+func (st *Suite) prepare() {
+	if st.prepared {
+		return
+	}
+	st.prepared = true
+	if st.BenchsF != nil {
+		st.BenchsF()
+	}
 }
 
-func (st *suite) AddBenchmark(name string, n, l int, run func(b *B)) {
-	st.benchs = append(st.benchs, &B{
+// connect runs InitF if the suite has one.
+func (st *Suite) connect() error {
+	if st.InitF != nil {
+		return st.InitF()
+	}
+	return nil
+}
+
+func (st *Suite) supports(d *Dialect) bool {
+	if len(st.Dialects) == 0 {
+		return true
+	}
+	for _, name := range st.Dialects {
+		if name == d.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// AddBenchmark registers a benchmark running n times with limit l, unless
+// the config file overrides them. A benchmark not matching -bench is
+// returned but not registered.
+func (st *Suite) AddBenchmark(name string, n, l int, run func(b *B)) *B {
+	n, l = override(st.Brand, name, n, l)
+	b := &B{
 		common: common{
-			signal: make(chan interface{}),
+			signal: make(chan interface{}, 1),
 		},
-		Name:  name,
-		Brand: st.Brand,
-		N:     n,
-		F:     run,
-		L:     l,
-	})
+		Name:   name,
+		Brand:  st.Brand,
+		N:      n,
+		F:      run,
+		L:      l,
+		pinned: ORM_PINNED[st.Brand+"/"+name],
+	}
+	if ORM_BENCH != nil && !ORM_BENCH.MatchString(name) {
+		return b
+	}
+	st.benchs = append(st.benchs, b)
 	if len(st.benchs) > benchmarksNums {
 		benchmarksNums = len(st.benchs)
 	}
+	return b
 }
 
-func (st *suite) run() {
+func (st *Suite) run() {
 	for _, b := range st.benchs {
-		b.run()
-		fmt.Printf("%25s: %6d ", b.Name, b.N)
-		fmt.Println(b.result.String())
+		for b.remaining(ORM_COUNT) > 0 {
+			if Interrupted() {
+				return
+			}
+			b.run()
+			fmt.Printf("%25s: %6d ", b.Name, b.N)
+			fmt.Println(b.result.String())
+			checkpoint()
+		}
 	}
 }
 
+// BrandNames are the names of the registered suites in the order they
+// were registered, -orm all runs them in this order.
 var BrandNames []string
-var benchmarks = make(map[string]*suite)
+var benchmarks = make(map[string]*Suite)
 var benchmarksNums = 0
 
-func NewSuite(name string) *suite {
-	s := new(suite)
-	s.Brand = name
-	benchmarks[name] = s
-	BrandNames = append(BrandNames, name)
+// Register adds s to the suites, its benchmarks are registered only when
+// the suite is listed or run. Like database/sql.Register it panics if
+// s.Brand is empty or already taken.
+func Register(s *Suite) {
+	if s == nil || s.Brand == "" {
+		panic("benchs: Register suite without a Brand")
+	}
+	if _, dup := benchmarks[s.Brand]; dup {
+		panic("benchs: Register called twice for suite " + s.Brand)
+	}
+	benchmarks[s.Brand] = s
+	BrandNames = append(BrandNames, s.Brand)
+}
+
+// NewSuite registers an empty suite named name, set its BenchsF and InitF
+// before the run starts.
+func NewSuite(name string) *Suite {
+	s := &Suite{Brand: name}
+	Register(s)
 	return s
 }
 
 func RunBenchmark(name string) {
-	if s, ok := benchmarks[name]; ok {
-		s.InitF()
-		if len(s.benchs) != benchmarksNums {
-			checkErr(fmt.Errorf("%s have not enough benchmarks", name))
-		}
+	if s := startSuite(name); s != nil {
 		s.run()
-	} else {
+	}
+}
+
+// startSuite checks that the named suite can run and connects it, it
+// returns nil if the suite is skipped.
+func startSuite(name string) *Suite {
+	s, ok := benchmarks[name]
+	if !ok {
 		checkErr(fmt.Errorf("not found benchmark suite %s", name))
 	}
+	if !s.supports(dialect) {
+		s.skipMsg = "only runs on " + strings.Join(s.Dialects, ", ")
+		fmt.Printf("skip %s: %s\n", name, s.skipMsg)
+		return nil
+	}
+	s.prepare()
+	if len(s.benchs) == 0 {
+		s.skipMsg = "no benchmark matches -bench"
+		fmt.Printf("skip %s: %s\n", name, s.skipMsg)
+		return nil
+	}
+	// MakeReport lines the benchmarks of all suites up by position
+	if len(s.benchs) != benchmarksNums {
+		checkErr(fmt.Errorf("%s has %d benchmarks matching -bench, other suites have %d", name, len(s.benchs), benchmarksNums))
+	}
+	calibrateOnce.Do(calibrate)
+	if err := s.connect(); err != nil {
+		msg := "connect: " + err.Error()
+		fmt.Printf("%s: %s\n", name, msg)
+		for _, b := range s.benchs {
+			if b.remaining(ORM_COUNT) > 0 {
+				b.result = &BenchmarkResult{Status: StatusFailed, FailedMsg: msg}
+			}
+		}
+		return nil
+	}
+	return s
+}
+
+// PrepareBenchmarks registers the benchmarks of the named suites before
+// any of them runs and returns the most benchmarks a suite has.
+func PrepareBenchmarks(names []string) int {
+	for _, name := range names {
+		if s, ok := benchmarks[name]; ok {
+			s.prepare()
+		}
+	}
+	return benchmarksNums
+}
+
+// SuiteBenchmarks returns the benchmarks registered by the named suite
+// with their results so far, call PrepareBenchmarks first.
+func SuiteBenchmarks(name string) (saved []SavedBenchmark) {
+	if s, ok := benchmarks[name]; ok {
+		for _, b := range s.benchs {
+			saved = append(saved, SavedBenchmark{Name: b.Name, N: b.N, L: b.L, Result: b.result, Samples: b.samples, Outliers: b.outliers, Unstable: b.unstable})
+		}
+	}
+	return
+}
+
+// ListBenchmarks prints the benchmarks of the named suites with their N
+// and L without touching a database.
+func ListBenchmarks(names []string) (result string) {
+	PrepareBenchmarks(names)
+	for _, name := range names {
+		s, ok := benchmarks[name]
+		if !ok {
+			continue
+		}
+		result += s.Brand
+		if !s.supports(dialect) {
+			result += " (only runs on " + strings.Join(s.Dialects, ", ") + ")"
+		}
+		result += "\n"
+		for _, b := range s.benchs {
+			result += fmt.Sprintf("%25s: N %6d  L %4d\n", b.Name, b.N, b.L)
+		}
+	}
+	return
 }
 
 type BList []*B
@@ -223,18 +1070,27 @@ func (s BList) Swap(i, j int) {
 	s[i], s[j] = s[j], s[i]
 }
 func (s BList) Less(i, j int) bool {
-	if s[i].failed {
-		return false
+	if si, sj := s[i].result.Status, s[j].result.Status; si != sj {
+		return si < sj
 	}
-	if s[j].failed {
-		return true
-	}
-	return s[i].duration < s[j].duration
+	return s[i].Stats().Mean() < s[j].Stats().Mean()
 }
 
-func MakeReport() (result string) {
-	var first string
+// Failed reports whether any benchmark that ran has failed.
+func Failed() bool {
+	for _, s := range benchmarks {
+		for _, b := range s.benchs {
+			if b.result != nil && b.result.Status >= StatusFailed {
+				return true
+			}
+		}
+	}
+	return false
+}
 
+// reportGroups returns the benchmarks that ran grouped by their position
+// in the suites, fastest first.
+func reportGroups() (groups []BList) {
 	for i := 0; i < benchmarksNums; i++ {
 
 		var benchs BList
@@ -253,27 +1109,127 @@ func MakeReport() (result string) {
 					continue
 				}
 
-				if len(first) == 0 {
-					first = name
-				}
-
 				benchs = append(benchs, b)
-
-				if name == first {
-					result += fmt.Sprintf("%6d times - %s\n", b.N, b.Name)
-				}
 			}
 		}
 
 		sort.Sort(benchs)
+		groups = append(groups, benchs)
+	}
+	return
+}
+
+func MakeReport() (result string) {
+	if environment != nil {
+		result += environment.String() + "\n"
+	}
+	groups := reportGroups()
+	for i, benchs := range groups {
 
+		// with -benchtime every brand may have run a different N
+		sameN := true
 		for _, b := range benchs {
-			result += fmt.Sprintf("%10s: ", b.Brand) + b.result.String() + "\n"
+			if b.result.Status == StatusOK && b.N != benchs[0].N {
+				sameN = false
+			}
+		}
+		if len(benchs) > 0 {
+			if sameN {
+				result += fmt.Sprintf("%6d times - %s\n", benchs[0].N, benchs[0].Name)
+			} else {
+				result += fmt.Sprintf("%6s times - %s\n", "auto", benchs[0].Name)
+			}
 		}
 
-		if i < benchmarksNums-1 {
+		for k, b := range benchs {
+			result += fmt.Sprintf("%10s: ", b.Brand)
+			if !sameN {
+				result += fmt.Sprintf("%8d x", b.N)
+			}
+			if b.result.Status != StatusOK || len(b.samples) < 2 {
+				result += b.result.String() + "\n"
+				if lat := b.result.LatencyString(); len(lat) > 0 {
+					result += fmt.Sprintf("%12s", "") + lat + "\n"
+				}
+				if w := b.result.WarmupString(); len(w) > 0 {
+					result += fmt.Sprintf("%12s", "") + w + "\n"
+				}
+				result += b.result.WorkersString(fmt.Sprintf("%12s", ""))
+				continue
+			}
+
+			stats := b.Stats()
+			result += stats.String()
+			// the ranking against the previous brand is within the noise
+			if k > 0 && benchs[k-1].result.Status == StatusOK && stats.Overlaps(benchs[k-1].Stats()) {
+				result += "  ~ tie with " + benchs[k-1].Brand
+			}
+			result += b.stability() + "\n"
+			if lat := stats.Latency().LatencyString(); len(lat) > 0 {
+				result += fmt.Sprintf("%12s", "") + lat + "\n"
+			}
+			if w := b.result.WarmupString(); len(w) > 0 {
+				result += fmt.Sprintf("%12s", "") + w + "\n"
+			}
+		}
+
+		if i < len(groups)-1 {
 			result += "\n"
 		}
 	}
+
+	if o := overheadReport(); len(o) > 0 {
+		result += "\n" + o
+	}
+	result += "\nCapabilities: \n\n" + MakeCapabilities()
 	return
 }
+
+// MakeCapabilities renders which ORM supports which operation.
+func MakeCapabilities() string {
+	var names []string
+	for _, name := range BrandNames {
+		if s, ok := benchmarks[name]; ok {
+			for i := len(names); i < len(s.benchs); i++ {
+				names = append(names, s.benchs[i].Name)
+			}
+		}
+	}
+
+	var buf strings.Builder
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprint(w, "\t")
+	for _, name := range names {
+		fmt.Fprintf(w, "%s\t", name)
+	}
+	fmt.Fprintln(w)
+
+	for _, brand := range BrandNames {
+		s, ok := benchmarks[brand]
+		if !ok || (len(s.benchs) == 0 && len(s.skipMsg) == 0) {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t", brand)
+		for i := range names {
+			mark := ""
+			switch {
+			case len(s.skipMsg) > 0:
+				mark = "n/a"
+			case i >= len(s.benchs) || s.benchs[i].result == nil:
+				mark = "-"
+			case s.benchs[i].result.Status == StatusFailed:
+				mark = "FAIL"
+			case s.benchs[i].result.Status == StatusTimeout:
+				mark = "TIMEOUT"
+			case s.benchs[i].result.Status == StatusInterrupted:
+				mark = "INTERRUPTED"
+			default:
+				mark = s.benchs[i].result.Status.String()
+			}
+			fmt.Fprintf(w, "%s\t", mark)
+		}
+		fmt.Fprintln(w)
+	}
+	w.Flush()
+	return buf.String()
+}
@@ -0,0 +1,559 @@
+package benchs
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSuites replaces the registered suites and the run settings for the
+// duration of the test, so fake suites can run without a database.
+func fakeSuites(t *testing.T) {
+	t.Helper()
+	oldBenchmarks, oldNames, oldNums := benchmarks, BrandNames, benchmarksNums
+	oldConcurrency, oldCount, oldCalibrate := ORM_CONCURRENCY, ORM_COUNT, ORM_CALIBRATE
+	oldCheckpoint, oldBench, oldTimeout, oldDialect := ORM_CHECKPOINT, ORM_BENCH, ORM_TIMEOUT, dialect
+	oldOutlier, oldRemeasure := ORM_OUTLIER, ORM_REMEASURE
+	oldBenchTime, oldWarmup, oldPinned := ORM_BENCHTIME, ORM_WARMUP, ORM_PINNED
+	t.Cleanup(func() {
+		benchmarks, BrandNames, benchmarksNums = oldBenchmarks, oldNames, oldNums
+		ORM_CONCURRENCY, ORM_COUNT, ORM_CALIBRATE = oldConcurrency, oldCount, oldCalibrate
+		ORM_CHECKPOINT, ORM_BENCH, ORM_TIMEOUT, dialect = oldCheckpoint, oldBench, oldTimeout, oldDialect
+		ORM_OUTLIER, ORM_REMEASURE = oldOutlier, oldRemeasure
+		ORM_BENCHTIME, ORM_WARMUP, ORM_PINNED = oldBenchTime, oldWarmup, oldPinned
+	})
+
+	benchmarks = make(map[string]*Suite)
+	BrandNames = nil
+	benchmarksNums = 0
+	ORM_CONCURRENCY = 1
+	ORM_COUNT = 1
+	ORM_CALIBRATE = false
+	ORM_CHECKPOINT = ""
+	ORM_BENCH = nil
+	ORM_TIMEOUT = 0
+	ORM_OUTLIER = 0
+	ORM_REMEASURE = 0
+	ORM_BENCHTIME = BenchTime{}
+	ORM_WARMUP = BenchTime{}
+	ORM_PINNED = nil
+	dialect = dialects["mysql"]
+}
+
+type fakeBench struct {
+	name string
+	f    func(b *B)
+}
+
+// newFakeSuite registers a suite running bs 10 times each.
+func newFakeSuite(brand string, bs ...fakeBench) *Suite {
+	st := NewSuite(brand)
+	st.BenchsF = func() {
+		for _, fb := range bs {
+			st.AddBenchmark(fb.name, 10, 0, fb.f)
+		}
+	}
+	st.InitF = func() error { return nil }
+	return st
+}
+
+// sleepOps is a benchmark whose operations take d each.
+func sleepOps(d time.Duration) func(b *B) {
+	return func(b *B) {
+		b.RunParallel(func(pb *PB) {
+			for pb.Next() {
+				time.Sleep(d)
+			}
+		})
+	}
+}
+
+// runFake runs f once as benchmark and returns its result.
+func runFake(t *testing.T, f func(b *B)) *BenchmarkResult {
+	t.Helper()
+	b := &B{
+		common: common{
+			signal: make(chan interface{}, 1),
+		},
+		Name: "fake",
+		N:    10,
+		F:    f,
+	}
+	b.run()
+	if b.result == nil {
+		t.Fatal("no result")
+	}
+	return b.result
+}
+
+func TestNsPerOp(t *testing.T) {
+	tests := []struct {
+		r                      BenchmarkResult
+		ns, allocs, bytesPerOp int64
+	}{
+		{BenchmarkResult{}, 0, 0, 0},
+		{BenchmarkResult{N: 4, T: 100, MemAllocs: 8, MemBytes: 400}, 25, 2, 100},
+		{BenchmarkResult{N: 3, T: 10}, 3, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.r.NsPerOp(); got != tt.ns {
+			t.Errorf("%+v NsPerOp() = %d, want %d", tt.r, got, tt.ns)
+		}
+		if got := tt.r.AllocsPerOp(); got != tt.allocs {
+			t.Errorf("%+v AllocsPerOp() = %d, want %d", tt.r, got, tt.allocs)
+		}
+		if got := tt.r.AllocedBytesPerOp(); got != tt.bytesPerOp {
+			t.Errorf("%+v AllocedBytesPerOp() = %d, want %d", tt.r, got, tt.bytesPerOp)
+		}
+	}
+	if got := (WorkerResult{N: 2, T: 50}).NsPerOp(); got != 25 {
+		t.Errorf("WorkerResult.NsPerOp() = %d, want 25", got)
+	}
+	if got := (WorkerResult{}).NsPerOp(); got != 0 {
+		t.Errorf("WorkerResult.NsPerOp() without ops = %d, want 0", got)
+	}
+}
+
+func TestResultString(t *testing.T) {
+	tests := []struct {
+		r    BenchmarkResult
+		want []string
+	}{
+		{BenchmarkResult{N: 1000, T: 2 * time.Millisecond, MemAllocs: 3000, MemBytes: 64000},
+			[]string{"2000 ns/op", "64 B/op", "3 allocs/op"}},
+		{BenchmarkResult{N: 1000, T: 5 * time.Microsecond}, []string{"5.00 ns/op"}},
+		{BenchmarkResult{N: 1000, T: 50 * time.Microsecond}, []string{"50.0 ns/op"}},
+		{BenchmarkResult{N: 2, T: time.Second, Workers: []WorkerResult{{N: 1}, {N: 1}}}, []string{"2.0 ops/s"}},
+		{BenchmarkResult{Status: StatusFailed, FailedMsg: "boom"}, []string{"FAIL: boom"}},
+		{BenchmarkResult{Status: StatusTimeout, FailedMsg: "after 1s"}, []string{"TIMEOUT: after 1s"}},
+		{BenchmarkResult{Status: StatusInterrupted, FailedMsg: "by signal"}, []string{"INTERRUPTED: by signal"}},
+		{BenchmarkResult{Status: StatusSkipped, SkippedMsg: "no bulk"}, []string{"skipped: no bulk"}},
+	}
+	for _, tt := range tests {
+		s := tt.r.String()
+		for _, want := range tt.want {
+			if !strings.Contains(s, want) {
+				t.Errorf("%+v String() = %q, want it to contain %q", tt.r, s, want)
+			}
+		}
+	}
+}
+
+func TestBListLess(t *testing.T) {
+	mk := func(brand string, status Status, ns ...time.Duration) *B {
+		b := &B{Brand: brand}
+		b.result = &BenchmarkResult{Status: status}
+		for _, d := range ns {
+			b.samples = append(b.samples, &BenchmarkResult{N: 1, T: d})
+		}
+		return b
+	}
+	list := BList{
+		mk("failed", StatusFailed),
+		mk("slow", StatusOK, 300),
+		mk("timeout", StatusTimeout),
+		mk("skipped", StatusSkipped),
+		mk("fast", StatusOK, 100, 120),
+		mk("interrupted", StatusInterrupted),
+	}
+	sort.Sort(list)
+
+	var got []string
+	for _, b := range list {
+		got = append(got, b.Brand)
+	}
+	want := "fast slow skipped failed timeout interrupted"
+	if strings.Join(got, " ") != want {
+		t.Errorf("sorted %v, want %s", got, want)
+	}
+}
+
+func TestLaunch(t *testing.T) {
+	fakeSuites(t)
+
+	t.Run("ok", func(t *testing.T) {
+		r := runFake(t, sleepOps(0))
+		if r.Status != StatusOK || r.N != 10 {
+			t.Errorf("got %v N %d, want ok N 10", r.Status, r.N)
+		}
+		if len(r.Workers) != 1 || r.Workers[0].N != 10 {
+			t.Errorf("workers %+v, want one with 10 ops", r.Workers)
+		}
+	})
+
+	t.Run("panic error", func(t *testing.T) {
+		r := runFake(t, func(b *B) {
+			panic(errors.New("boom"))
+		})
+		if r.Status != StatusFailed || !strings.Contains(r.FailedMsg, "boom") {
+			t.Errorf("got %v %q, want failed with boom", r.Status, r.FailedMsg)
+		}
+		if r.Error == nil || r.Error.Iteration != -1 {
+			t.Errorf("error %+v, want one outside of RunParallel", r.Error)
+		}
+	})
+
+	t.Run("panic value in worker", func(t *testing.T) {
+		ORM_CONCURRENCY = 4
+		defer func() { ORM_CONCURRENCY = 1 }()
+		r := runFake(t, func(b *B) {
+			b.RunParallel(func(pb *PB) {
+				for pb.Next() {
+					panic("worker")
+				}
+			})
+		})
+		if r.Status != StatusFailed || !strings.Contains(r.FailedMsg, "panic: worker") {
+			t.Errorf("got %v %q, want failed with panic: worker", r.Status, r.FailedMsg)
+		}
+	})
+
+	t.Run("FailNow", func(t *testing.T) {
+		after := false
+		r := runFake(t, func(b *B) {
+			b.FailNow()
+			after = true
+		})
+		if r.Status != StatusFailed || r.FailedMsg != "FailNow called" {
+			t.Errorf("got %v %q, want failed with FailNow called", r.Status, r.FailedMsg)
+		}
+		if after {
+			t.Error("benchmark went on after FailNow")
+		}
+	})
+
+	t.Run("Fatal in worker", func(t *testing.T) {
+		ORM_CONCURRENCY = 4
+		defer func() { ORM_CONCURRENCY = 1 }()
+		after := false
+		r := runFake(t, func(b *B) {
+			b.RunParallel(func(pb *PB) {
+				for pb.Next() {
+					b.Fatal(errors.New("bad row"))
+				}
+			})
+			after = true
+		})
+		if r.Status != StatusFailed || r.Error == nil || r.Error.Msg != "bad row" {
+			t.Fatalf("got %v %+v, want failed with bad row", r.Status, r.Error)
+		}
+		if r.Error.Iteration < 0 {
+			t.Errorf("iteration %d, want one inside RunParallel", r.Error.Iteration)
+		}
+		if after {
+			t.Error("benchmark went on after Fatal in RunParallel")
+		}
+	})
+
+	t.Run("Skip", func(t *testing.T) {
+		r := runFake(t, func(b *B) {
+			b.Skip("no bulk insert")
+		})
+		if r.Status != StatusSkipped || r.SkippedMsg != "no bulk insert" {
+			t.Errorf("got %v %q, want skipped with no bulk insert", r.Status, r.SkippedMsg)
+		}
+	})
+}
+
+// sink keeps allocations of the tests from being optimized away.
+var sink []byte
+
+func TestAbandoned(t *testing.T) {
+	fakeSuites(t)
+	ORM_TIMEOUT = 10 * time.Millisecond
+
+	// ignores its context and returns only after run gave up on it
+	release := make(chan struct{})
+	returned := make(chan struct{})
+	b := &B{
+		common: common{
+			signal: make(chan interface{}, 1),
+		},
+		Name: "stuck",
+		N:    1,
+		F: func(b *B) {
+			defer close(returned)
+			<-release
+		},
+	}
+	b.run()
+	if b.result.Status != StatusTimeout || !strings.Contains(b.result.FailedMsg, "abandoned") {
+		t.Fatalf("got %v %q, want an abandoned timeout", b.result.Status, b.result.FailedMsg)
+	}
+	close(release)
+	<-returned
+	select {
+	case r := <-b.signal:
+		t.Errorf("the abandoned run sent %v to the next one", r)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSetupAllocs(t *testing.T) {
+	fakeSuites(t)
+
+	// a plain loop, setup allocating 10000 times
+	r := runFake(t, func(b *B) {
+		wrapExecute(b, func() {
+			for i := 0; i < 10000; i++ {
+				sink = make([]byte, 1024)
+			}
+		})
+		for i := 0; i < b.N; i++ {
+			wrapExecute(b, func() {
+				sink = make([]byte, 1024)
+			})
+		}
+	})
+	if r.AllocsPerOp() > 0 || r.AllocedBytesPerOp() > 0 {
+		t.Errorf("plain loop: %d allocs/op %d B/op, want the setup left out", r.AllocsPerOp(), r.AllocedBytesPerOp())
+	}
+
+	r = runFake(t, func(b *B) {
+		wrapExecute(b, func() {
+			for i := 0; i < 10000; i++ {
+				sink = make([]byte, 1024)
+			}
+		})
+		for i := 0; i < b.N; i++ {
+			sink = make([]byte, 1024)
+		}
+	})
+	if r.AllocsPerOp() != 1 || r.AllocedBytesPerOp() < 1024 || r.AllocedBytesPerOp() > 2048 {
+		t.Errorf("plain loop: %d allocs/op %d B/op, want 1 of 1 KiB", r.AllocsPerOp(), r.AllocedBytesPerOp())
+	}
+
+	r = runFake(t, func(b *B) {
+		for i := 0; i < 10000; i++ {
+			sink = make([]byte, 1024)
+		}
+		b.RunParallel(func(pb *PB) {
+			for pb.Next() {
+			}
+		})
+	})
+	if r.AllocsPerOp() > 0 {
+		t.Errorf("RunParallel: %d allocs/op, want the setup before it left out", r.AllocsPerOp())
+	}
+}
+
+func TestBenchTimePlainLoop(t *testing.T) {
+	fakeSuites(t)
+
+	// a count applies to plain loops too
+	ORM_BENCHTIME = BenchTime{N: 3}
+	ops := 0
+	r := runFake(t, func(b *B) {
+		for i := 0; i < b.N; i++ {
+			ops++
+		}
+	})
+	if r.N != 3 || ops != 3 {
+		t.Errorf("-benchtime 3x: N %d, %d ops, want 3", r.N, ops)
+	}
+
+	// a duration is calibrated by RunParallel only, the plain loop keeps
+	// its N and is warned about
+	ORM_BENCHTIME = BenchTime{D: time.Millisecond}
+	b := &B{
+		common: common{signal: make(chan interface{}, 1)},
+		Name:   "fake",
+		N:      10,
+		F: func(b *B) {
+			for i := 0; i < b.N; i++ {
+			}
+		},
+	}
+	b.run()
+	if b.result.N != 10 || !b.warned {
+		t.Errorf("-benchtime 1ms: N %d, warned %v, want 10 and a warning", b.result.N, b.warned)
+	}
+
+	b.warned = false
+	b.F = func(b *B) {
+		b.RunParallel(func(pb *PB) {
+			for pb.Next() {
+			}
+		})
+	}
+	b.run()
+	if b.warned {
+		t.Error("-benchtime 1ms: RunParallel warned")
+	}
+}
+
+func TestSetupFailure(t *testing.T) {
+	fakeSuites(t)
+	oldSource := ORM_SOURCE
+	t.Cleanup(func() { ORM_SOURCE = oldSource })
+	ORM_SOURCE = "root:root@tcp(127.0.0.1:1)/test?timeout=1s"
+
+	// recreating the table fails, the benchmark does and the run goes on
+	r := runFake(t, func(b *B) {
+		insertWorkload(b, nil)
+	})
+	if r.Status != StatusFailed || r.Error == nil {
+		t.Errorf("got %v %q, want failed with the connection error", r.Status, r.FailedMsg)
+	}
+}
+
+func TestMakeReport(t *testing.T) {
+	fakeSuites(t)
+	newFakeSuite("alpha",
+		fakeBench{"Insert", sleepOps(time.Millisecond)},
+		fakeBench{"Read", sleepOps(0)},
+	)
+	newFakeSuite("beta",
+		fakeBench{"Insert", sleepOps(0)},
+		fakeBench{"Read", sleepOps(time.Millisecond)},
+	)
+	newFakeSuite("gamma",
+		fakeBench{"Insert", func(b *B) { b.Fatal("broken") }},
+		fakeBench{"Read", func(b *B) { b.Skip("no reads") }},
+	)
+	for _, name := range BrandNames {
+		RunBenchmark(name)
+	}
+	report := MakeReport()
+
+	insert := strings.Index(report, "10 times - Insert")
+	read := strings.Index(report, "10 times - Read")
+	capabilities := strings.Index(report, "Capabilities:")
+	if insert < 0 || read < insert || capabilities < read {
+		t.Fatalf("want the Insert group, the Read group and the capabilities in order:\n%s", report)
+	}
+
+	// within a group the fastest comes first, failed and skipped last
+	order := func(section string, brands ...string) {
+		t.Helper()
+		last := -1
+		for _, brand := range brands {
+			i := strings.Index(section, brand+":")
+			if i < last {
+				t.Errorf("%s comes before %s in\n%s", brands, brand, section)
+			}
+			last = i
+		}
+	}
+	order(report[insert:read], "beta", "alpha", "gamma")
+	order(report[read:capabilities], "alpha", "beta", "gamma")
+	if !strings.Contains(report[insert:read], "FAIL: broken") {
+		t.Errorf("Insert group lacks the failure of gamma:\n%s", report[insert:read])
+	}
+	if !strings.Contains(report[read:capabilities], "skipped: no reads") {
+		t.Errorf("Read group lacks the skip of gamma:\n%s", report[read:capabilities])
+	}
+
+	caps := report[capabilities:]
+	for _, want := range []string{"alpha", "ok", "FAIL", "skipped"} {
+		if !strings.Contains(caps, want) {
+			t.Errorf("capabilities lack %q:\n%s", want, caps)
+		}
+	}
+	if !Failed() {
+		t.Error("Failed() = false with a failed benchmark")
+	}
+}
+
+func TestReportFromSavedResults(t *testing.T) {
+	fakeSuites(t)
+	ORM_COUNT = 2
+	newFakeSuite("alpha", fakeBench{"Read", sleepOps(time.Millisecond)})
+	RunBenchmark("alpha")
+	live := MakeReport()
+	if !strings.Contains(live, "p50") {
+		t.Fatalf("no latencies in\n%s", live)
+	}
+
+	data, err := json.Marshal(CollectResults())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fakeSuites(t)
+	saved := new(Results)
+	if err := json.Unmarshal(data, saved); err != nil {
+		t.Fatal(err)
+	}
+	saved.Restore()
+	if restored := MakeReport(); restored != live {
+		t.Errorf("report of the saved results\n%s\nwant the live one\n%s", restored, live)
+	}
+}
+
+func TestReportRowMeans(t *testing.T) {
+	fakeSuites(t)
+	b := &B{Name: "Read", Brand: "alpha", N: 10}
+	b.samples = []*BenchmarkResult{
+		{N: 10, T: 1000, MemAllocs: 20, MemBytes: 1000, Status: StatusOK},
+		{N: 10, T: 3000, MemAllocs: 40, MemBytes: 3000, Status: StatusOK},
+	}
+	b.result = b.samples[1]
+	row := reportRow(b)
+	// ns/op, B/op and allocs/op
+	if got := strings.Join(row[4:8], " "); got != "200 141 200 3" {
+		t.Errorf("ns/op stddev B/op allocs/op = %s, want the means 200 141 200 3", got)
+	}
+}
+
+func TestAddBenchmarkFilter(t *testing.T) {
+	fakeSuites(t)
+	ORM_BENCH = regexp.MustCompile("^Read$")
+	st := newFakeSuite("alpha",
+		fakeBench{"Insert", sleepOps(0)},
+		fakeBench{"Read", sleepOps(0)},
+	)
+	if n := PrepareBenchmarks([]string{"alpha"}); n != 1 {
+		t.Fatalf("PrepareBenchmarks = %d, want 1", n)
+	}
+	if st.find("Insert") != nil || st.find("Read") == nil {
+		t.Errorf("registered %v, want only Read", SuiteBenchmarks("alpha"))
+	}
+}
+
+func TestSuiteWithoutInitF(t *testing.T) {
+	fakeSuites(t)
+	Register(&Suite{Brand: "plain", BenchsF: func() {
+		benchmarks["plain"].AddBenchmark("Read", 10, 0, sleepOps(0))
+	}})
+	RunBenchmark("plain")
+	if r := benchmarks["plain"].benchs[0].result; r == nil || r.Status != StatusOK {
+		t.Errorf("got %v, want the benchmark run", r)
+	}
+}
+
+func TestConnectFailure(t *testing.T) {
+	fakeSuites(t)
+	newFakeSuite("alpha", fakeBench{"Read", sleepOps(0)})
+	broken := newFakeSuite("beta", fakeBench{"Read", sleepOps(0)})
+	broken.InitF = func() error { return errors.New("connection refused") }
+
+	PrepareBenchmarks(BrandNames)
+	RunInterleaved(BrandNames, rand.New(rand.NewSource(1)))
+
+	if r := benchmarks["alpha"].benchs[0].result; r == nil || r.Status != StatusOK {
+		t.Errorf("alpha got %v, want it run", r)
+	}
+	r := broken.benchs[0].result
+	if r == nil || r.Status != StatusFailed || !strings.Contains(r.FailedMsg, "connect: connection refused") {
+		t.Errorf("beta got %v, want it failed with the connection error", r)
+	}
+	if !Failed() {
+		t.Error("Failed() = false")
+	}
+}
+
+func TestRegisterTwice(t *testing.T) {
+	fakeSuites(t)
+	NewSuite("alpha")
+	defer func() {
+		if recover() == nil {
+			t.Error("registering alpha twice didn't panic")
+		}
+	}()
+	Register(&Suite{Brand: "alpha"})
+}
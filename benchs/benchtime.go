@@ -0,0 +1,65 @@
+package benchs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BenchTime is the -benchtime flag: either a duration every benchmark
+// should run for, or a fixed iteration count written as "Nx".
+// The zero value keeps the N given to AddBenchmark.
+type BenchTime struct {
+	D time.Duration
+	N int
+}
+
+func (t *BenchTime) String() string {
+	if t.N > 0 {
+		return fmt.Sprintf("%dx", t.N)
+	}
+	if t.D > 0 {
+		return t.D.String()
+	}
+	return ""
+}
+
+func (t *BenchTime) Set(s string) error {
+	if strings.HasSuffix(s, "x") {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid count %q", s)
+		}
+		*t = BenchTime{N: n}
+		return nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return fmt.Errorf("invalid duration %q", s)
+	}
+	*t = BenchTime{D: d}
+	return nil
+}
+
+// predictN estimates the iterations needed to run for goal, given that
+// last iterations took took. Like testing.B it grows by at most 100x and
+// overshoots by 20% so the loop doesn't end just short of the goal.
+func predictN(goal, took time.Duration, last int) int {
+	prevns := took.Nanoseconds()
+	if prevns <= 0 {
+		prevns = 1
+	}
+	n := goal.Nanoseconds() * int64(last) / prevns
+	n += n / 5
+	if max := 100 * int64(last); n > max {
+		n = max
+	}
+	if n <= int64(last) {
+		n = int64(last) + 1
+	}
+	if n > 1e9 {
+		n = 1e9
+	}
+	return int(n)
+}
@@ -0,0 +1,194 @@
+package benchs
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// budgetMetrics are the metrics a budget can limit, each the mean of the
+// -count samples.
+var budgetMetrics = map[string]func(s Stats) []float64{
+	"ns/op":     Stats.NsPerOp,
+	"B/op":      Stats.BytesPerOp,
+	"allocs/op": Stats.AllocsPerOp,
+}
+
+// Budget is a rule of a budgets file limiting a metric of one benchmark,
+// either to a number or to a factor of the same metric of another
+// benchmark:
+//
+//	gorm Insert allocs/op <= 120
+//	xorm Read ns/op <= 1.3 x raw Read
+type Budget struct {
+	Rule   string
+	Brand  string
+	Name   string
+	Metric string
+	// Strict is set for <, a limit reached is a violation too.
+	Strict bool
+	Limit  float64
+	// RefBrand and RefName make Limit a factor of their metric.
+	RefBrand string
+	RefName  string
+}
+
+// ParseBudget parses one rule, the factor may be written as 1.3 ×, 1.3 x
+// or 1.3 * and may be left out for 1.
+func ParseBudget(rule string) (*Budget, error) {
+	bg := &Budget{Rule: rule}
+	op := "<="
+	i := strings.Index(rule, op)
+	if i < 0 {
+		op = "<"
+		i = strings.Index(rule, op)
+	}
+	if i < 0 {
+		return nil, fmt.Errorf("%q: expected <= or <", rule)
+	}
+	bg.Strict = op == "<"
+
+	lhs := strings.Fields(rule[:i])
+	if len(lhs) < 3 || budgetMetrics[lhs[len(lhs)-1]] == nil {
+		return nil, fmt.Errorf("%q: expected orm operation metric on the left, the metric one of ns/op, B/op, allocs/op", rule)
+	}
+	bg.Brand = lhs[0]
+	bg.Name = strings.Join(lhs[1:len(lhs)-1], " ")
+	bg.Metric = lhs[len(lhs)-1]
+
+	rhs := strings.Fields(rule[i+len(op):])
+	if len(rhs) == 0 {
+		return nil, fmt.Errorf("%q: expected a limit on the right", rule)
+	}
+	bg.Limit = 1
+	if limit, err := strconv.ParseFloat(strings.TrimRight(rhs[0], "×x*"), 64); err == nil {
+		bg.Limit = limit
+		if len(rhs) == 1 {
+			return bg, nil
+		}
+		rhs = rhs[1:]
+		if rhs[0] == "×" || rhs[0] == "x" || rhs[0] == "*" {
+			rhs = rhs[1:]
+		}
+	}
+	if len(rhs) < 2 {
+		return nil, fmt.Errorf("%q: expected a number or [factor ×] orm operation on the right", rule)
+	}
+	bg.RefBrand = rhs[0]
+	bg.RefName = strings.Join(rhs[1:], " ")
+	return bg, nil
+}
+
+// ReadBudgets loads a budgets file, one rule per line, blank lines and
+// lines starting with # are ignored.
+func ReadBudgets(path string) ([]*Budget, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var budgets []*Budget
+	sc := bufio.NewScanner(f)
+	for line := 1; sc.Scan(); line++ {
+		rule := strings.TrimSpace(sc.Text())
+		if rule == "" || strings.HasPrefix(rule, "#") {
+			continue
+		}
+		bg, err := ParseBudget(rule)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, line, err)
+		}
+		budgets = append(budgets, bg)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return budgets, nil
+}
+
+// BudgetResult is a budget checked against results.
+type BudgetResult struct {
+	*Budget
+	Value float64
+	// Max is the limit the value was checked against.
+	Max  float64
+	Pass bool
+	// Msg tells why the budget couldn't be checked.
+	Msg string
+}
+
+// metric returns the mean of metric over the samples of a benchmark.
+func (r *Results) metric(brand, name, metric string) (float64, error) {
+	sb, ok := r.Find(brand, name)
+	if !ok {
+		return 0, fmt.Errorf("%s %s didn't run", brand, name)
+	}
+	if sb.Result.Status != StatusOK || len(sb.Samples) == 0 {
+		return 0, fmt.Errorf("%s %s: %s", brand, name, sb.Result.Status)
+	}
+	return mean(budgetMetrics[metric](sb.Stats())), nil
+}
+
+// CheckBudgets checks every budget against r, a budget whose benchmarks
+// didn't succeed fails.
+func CheckBudgets(budgets []*Budget, r *Results) (results []BudgetResult) {
+	for _, bg := range budgets {
+		br := BudgetResult{Budget: bg, Max: bg.Limit}
+		var err error
+		br.Value, err = r.metric(bg.Brand, bg.Name, bg.Metric)
+		if err == nil && bg.RefBrand != "" {
+			var ref float64
+			ref, err = r.metric(bg.RefBrand, bg.RefName, bg.Metric)
+			br.Max = bg.Limit * ref
+		}
+		if err != nil {
+			br.Msg = err.Error()
+		} else {
+			br.Pass = br.Value < br.Max || !bg.Strict && br.Value == br.Max
+		}
+		results = append(results, br)
+	}
+	return
+}
+
+// BudgetsPassed reports whether all budgets passed.
+func BudgetsPassed(results []BudgetResult) bool {
+	for _, br := range results {
+		if !br.Pass {
+			return false
+		}
+	}
+	return true
+}
+
+// MakeBudgetReport renders the checked budgets as a pass/fail table.
+func MakeBudgetReport(results []BudgetResult) string {
+	var buf strings.Builder
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "rule\tvalue\tlimit\tresult\t")
+	for _, br := range results {
+		status := "PASS"
+		if !br.Pass {
+			status = "FAIL"
+		}
+		if br.Msg != "" {
+			fmt.Fprintf(w, "%s\t\t\t%s: %s\t\n", br.Rule, status, br.Msg)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t\n", br.Rule, budgetValue(br.Value), budgetValue(br.Max), status)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// budgetValue formats a metric, small ones like allocs/op with decimals.
+func budgetValue(v float64) string {
+	if v < 100 {
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	}
+	return strconv.FormatFloat(v, 'f', 0, 64)
+}
@@ -0,0 +1,99 @@
+package benchs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBudget(t *testing.T) {
+	tests := []struct {
+		rule string
+		want Budget
+	}{
+		{"gorm Insert allocs/op <= 120", Budget{Brand: "gorm", Name: "Insert", Metric: "allocs/op", Limit: 120}},
+		{"xorm Read ns/op <= 1.3 × raw Read", Budget{Brand: "xorm", Name: "Read", Metric: "ns/op", Limit: 1.3, RefBrand: "raw", RefName: "Read"}},
+		{"xorm Read ns/op <= 1.3x raw Read", Budget{Brand: "xorm", Name: "Read", Metric: "ns/op", Limit: 1.3, RefBrand: "raw", RefName: "Read"}},
+		{"pg MultiRead limit 1000 B/op < 2 * raw MultiRead limit 1000", Budget{Brand: "pg", Name: "MultiRead limit 1000", Metric: "B/op", Strict: true, Limit: 2, RefBrand: "raw", RefName: "MultiRead limit 1000"}},
+		{"sqlx Update ns/op <= raw Update", Budget{Brand: "sqlx", Name: "Update", Metric: "ns/op", Limit: 1, RefBrand: "raw", RefName: "Update"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseBudget(tt.rule)
+		if err != nil {
+			t.Errorf("ParseBudget(%q): %v", tt.rule, err)
+			continue
+		}
+		tt.want.Rule = tt.rule
+		if *got != tt.want {
+			t.Errorf("ParseBudget(%q) = %+v, want %+v", tt.rule, *got, tt.want)
+		}
+	}
+
+	for _, rule := range []string{
+		"gorm Insert allocs/op 120",
+		"gorm allocs/op <= 120",
+		"gorm Insert ops <= 120",
+		"gorm Insert allocs/op <=",
+		"gorm Insert allocs/op <= 2 x raw",
+	} {
+		if _, err := ParseBudget(rule); err == nil {
+			t.Errorf("ParseBudget(%q) passed", rule)
+		}
+	}
+}
+
+func TestCheckBudgets(t *testing.T) {
+	saved := func(name string, rs ...*BenchmarkResult) SavedBenchmark {
+		return SavedBenchmark{Name: name, Result: rs[len(rs)-1], Samples: rs}
+	}
+	r := &Results{Suites: []SuiteResult{
+		{Brand: "raw", Benchmarks: []SavedBenchmark{
+			saved("Read", &BenchmarkResult{N: 10, T: 1000, MemAllocs: 100, Status: StatusOK}),
+		}},
+		{Brand: "xorm", Benchmarks: []SavedBenchmark{
+			// ns/op 120 and 140, a mean of 130
+			saved("Read",
+				&BenchmarkResult{N: 10, T: 1200, MemAllocs: 500, Status: StatusOK},
+				&BenchmarkResult{N: 10, T: 1400, MemAllocs: 500, Status: StatusOK}),
+			{Name: "Insert", Result: &BenchmarkResult{Status: StatusFailed}},
+		}},
+	}}
+
+	tests := []struct {
+		rule string
+		pass bool
+		max  float64
+	}{
+		{"xorm Read ns/op <= 1.3 × raw Read", true, 130},
+		{"xorm Read ns/op < 1.3 × raw Read", false, 130},
+		{"xorm Read ns/op <= 1.2 × raw Read", false, 120},
+		{"xorm Read allocs/op <= 50", true, 50},
+		{"xorm Read allocs/op <= 4 x raw Read", false, 40},
+		{"xorm Insert ns/op <= 1000", false, 1000},
+		{"gorm Read ns/op <= 1000", false, 1000},
+	}
+	var budgets []*Budget
+	for _, tt := range tests {
+		bg, err := ParseBudget(tt.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		budgets = append(budgets, bg)
+	}
+	checked := CheckBudgets(budgets, r)
+	for i, tt := range tests {
+		br := checked[i]
+		if br.Pass != tt.pass || br.Max != tt.max {
+			t.Errorf("%s: pass %v max %v, want pass %v max %v (%s)", tt.rule, br.Pass, br.Max, tt.pass, tt.max, br.Msg)
+		}
+	}
+	if BudgetsPassed(checked) || !BudgetsPassed(checked[:1]) {
+		t.Error("BudgetsPassed doesn't tell a failing budget")
+	}
+
+	table := MakeBudgetReport(checked)
+	for _, want := range []string{"PASS", "FAIL: xorm Insert: failed", "FAIL: gorm Read didn't run"} {
+		if !strings.Contains(table, want) {
+			t.Errorf("table misses %q:\n%s", want, table)
+		}
+	}
+}
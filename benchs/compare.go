@@ -0,0 +1,105 @@
+package benchs
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// significance is the p-value below which Compare reports a change, like
+// benchstat.
+const significance = 0.05
+
+// delta formats the change from old to cur in percent.
+func delta(old, cur float64) string {
+	if old == 0 {
+		if cur == 0 {
+			return "+0.00%"
+		}
+		return "?"
+	}
+	return fmt.Sprintf("%+.2f%%", (cur-old)/old*100)
+}
+
+// spread formats the standard deviation of vs relative to their mean.
+func spread(vs []float64) string {
+	m := mean(vs)
+	if m == 0 || len(vs) < 2 {
+		return ""
+	}
+	return fmt.Sprintf("±%.0f%%", stddev(vs)/m*100)
+}
+
+// compareSamples returns the samples of sb, results saved before the
+// samples were kept have the last one only.
+func compareSamples(sb SavedBenchmark) Stats {
+	if len(sb.Samples) == 0 && sb.Result != nil && sb.Result.Status == StatusOK {
+		return Stats{Samples: []*BenchmarkResult{sb.Result}}
+	}
+	return sb.Stats()
+}
+
+// Compare renders ns/op, B/op and allocs/op of every benchmark found in
+// both old and cur, one table each like benchstat. The change between the
+// means is tested with the Mann-Whitney U test over the samples, one that
+// isn't significant is shown as ~.
+func Compare(old, cur *Results) string {
+	type pair struct {
+		brand  string
+		old    SavedBenchmark
+		cur    SavedBenchmark
+		failed bool
+	}
+	var pairs []pair
+	var missing []string
+	for _, sr := range cur.Suites {
+		for _, sb := range sr.Benchmarks {
+			ob, ok := old.Find(sr.Brand, sb.Name)
+			if !ok {
+				missing = append(missing, sr.Brand+" "+sb.Name)
+				continue
+			}
+			failed := ob.Result.Status != StatusOK || sb.Result.Status != StatusOK
+			pairs = append(pairs, pair{sr.Brand, ob, sb, failed})
+		}
+	}
+
+	var buf strings.Builder
+	metrics := []struct {
+		name   string
+		values func(s Stats) []float64
+	}{
+		{"ns/op", Stats.NsPerOp},
+		{"B/op", Stats.BytesPerOp},
+		{"allocs/op", Stats.AllocsPerOp},
+	}
+	for i, metric := range metrics {
+		if i > 0 {
+			buf.WriteString("\n")
+		}
+		w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "orm\toperation\told %s\t\tnew %s\t\tdelta\t\n", metric.name, metric.name)
+		for _, p := range pairs {
+			fmt.Fprintf(w, "%s\t%s\t", p.brand, p.cur.Name)
+			if p.failed {
+				fmt.Fprintf(w, "%s\t\t%s\t\t\t\n", p.old.Result.Status, p.cur.Result.Status)
+				continue
+			}
+			o := metric.values(compareSamples(p.old))
+			n := metric.values(compareSamples(p.cur))
+			pv := MannWhitneyU(o, n)
+			change := "~"
+			if pv < significance {
+				change = delta(mean(o), mean(n))
+			}
+			fmt.Fprintf(w, "%.0f\t%s\t%.0f\t%s\t%s (p=%.3f n=%d+%d)\t\n",
+				mean(o), spread(o), mean(n), spread(n), change, pv, len(o), len(n))
+		}
+		w.Flush()
+	}
+
+	if len(missing) > 0 {
+		buf.WriteString("\nnot in the old results: " + strings.Join(missing, ", ") + "\n")
+	}
+	return buf.String()
+}
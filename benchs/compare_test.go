@@ -0,0 +1,80 @@
+package benchs
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestMannWhitneyU(t *testing.T) {
+	tests := []struct {
+		x, y []float64
+		p    float64
+	}{
+		{[]float64{1, 2, 3, 4, 5}, []float64{6, 7, 8, 9, 10}, 2.0 / 252},
+		{[]float64{6, 7, 8, 9, 10}, []float64{1, 2, 3, 4, 5}, 2.0 / 252},
+		{[]float64{1, 2, 3}, []float64{4, 5, 6}, 0.1},
+		{[]float64{1, 3, 5, 7, 9}, []float64{2, 4, 6, 8, 10}, 174.0 / 252},
+		{[]float64{10, 10}, []float64{10, 10}, 1},
+		{[]float64{1}, []float64{2}, 1},
+		{nil, []float64{2}, 1},
+		// ties use the normal approximation
+		{[]float64{10, 10, 10, 10, 10}, []float64{12, 12, 12, 12, 12}, 0.00398},
+	}
+	for _, tt := range tests {
+		if p := MannWhitneyU(tt.x, tt.y); math.Abs(p-tt.p) > 1e-5 {
+			t.Errorf("MannWhitneyU(%v, %v) = %.5f, want %.5f", tt.x, tt.y, p, tt.p)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	saved := func(name string, ns ...int) SavedBenchmark {
+		rs := samples(ns...)
+		for _, r := range rs {
+			r.N = 10
+			r.T *= 10
+			r.MemAllocs = 30
+		}
+		return SavedBenchmark{Name: name, Result: rs[len(rs)-1], Samples: rs}
+	}
+	old := &Results{Suites: []SuiteResult{{Brand: "raw", Benchmarks: []SavedBenchmark{
+		saved("Insert", 100, 101, 102, 103, 104),
+		saved("Read", 100, 102, 104, 106, 108),
+		{Name: "Update", Result: &BenchmarkResult{Status: StatusFailed}},
+	}}}}
+	cur := &Results{Suites: []SuiteResult{{Brand: "raw", Benchmarks: []SavedBenchmark{
+		saved("Insert", 200, 201, 202, 203, 204),
+		saved("Read", 101, 103, 105, 107, 109),
+		saved("Update", 100),
+		saved("Delete", 100),
+	}}}}
+	report := Compare(old, cur)
+
+	line := func(section, name string) string {
+		t.Helper()
+		s := report[strings.Index(report, section):]
+		for _, l := range strings.Split(s, "\n") {
+			if strings.HasPrefix(l, "raw") && strings.Contains(l, name) {
+				return l
+			}
+		}
+		t.Fatalf("no %s line in %s:\n%s", name, section, report)
+		return ""
+	}
+	if l := line("old ns/op", "Insert"); !strings.Contains(l, "+98.04% (p=0.008 n=5+5)") {
+		t.Errorf("Insert doubled, got %q", l)
+	}
+	if l := line("old ns/op", "Read"); !strings.Contains(l, "~ (p=0.690 n=5+5)") {
+		t.Errorf("Read within the noise, got %q", l)
+	}
+	if l := line("old ns/op", "Update"); !strings.Contains(l, "failed") {
+		t.Errorf("Update failed before, got %q", l)
+	}
+	if l := line("old allocs/op", "Insert"); !strings.Contains(l, "~ (p=1.000 n=5+5)") {
+		t.Errorf("Insert allocs unchanged, got %q", l)
+	}
+	if !strings.Contains(report, "not in the old results: raw Delete") {
+		t.Errorf("Delete isn't reported missing:\n%s", report)
+	}
+}
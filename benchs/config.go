@@ -0,0 +1,192 @@
+package benchs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Config is a run configuration file holding named profiles, e.g.
+//
+//	{"profiles": {"nightly-pg": {"dialect": "postgres", "multi": 5,
+//	    "benchmarks": {"Insert": {"n": 5000}, "gorm/Read": {"n": 100}}}}}
+type Config struct {
+	Profiles map[string]*Profile `json:"profiles"`
+}
+
+// Profile sets the run flags of the same name, flags given on the command
+// line take precedence. Benchmarks overrides N and L of the benchmarks by
+// name or by orm/name.
+type Profile struct {
+	Dialect      string   `json:"dialect"`
+	Source       string   `json:"source"`
+	Orm          []string `json:"orm"`
+	Bench        string   `json:"bench"`
+	Multi        int      `json:"multi"`
+	MaxIdle      int      `json:"max_idle"`
+	MaxConn      int      `json:"max_conn"`
+	Concurrency  int      `json:"concurrency"`
+	Count        int      `json:"count"`
+	Benchtime    string   `json:"benchtime"`
+	Warmup       string   `json:"warmup"`
+	Timeout      string   `json:"timeout"`
+	TotalTimeout string   `json:"total_timeout"`
+	Schedule     string   `json:"schedule"`
+	Seed         int64    `json:"seed"`
+	Outlier      float64  `json:"outlier"`
+	Remeasure    int      `json:"remeasure"`
+	Budgets      string   `json:"budgets"`
+
+	Benchmarks map[string]BenchmarkOverride `json:"benchmarks"`
+}
+
+// BenchmarkOverride replaces the n and l a suite passes to AddBenchmark,
+// zero keeps the suite's value.
+type BenchmarkOverride struct {
+	N int `json:"n,omitempty"`
+	L int `json:"l,omitempty"`
+}
+
+// ReadConfig loads a config file, unknown keys are an error so typos
+// don't silently fall back to the defaults.
+func ReadConfig(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	c := new(Config)
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(c); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if len(c.Profiles) == 0 {
+		return nil, fmt.Errorf("%s: no profiles", path)
+	}
+	return c, nil
+}
+
+// Profile returns the named profile, the only one if name is empty, and
+// its name.
+func (c *Config) Profile(name string) (string, *Profile, error) {
+	var names []string
+	for n := range c.Profiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	if name == "" {
+		if len(names) == 1 {
+			return names[0], c.Profiles[names[0]], nil
+		}
+		return "", nil, fmt.Errorf("choose a profile with -profile: %s", strings.Join(names, ", "))
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown profile %s, expected one of %s", name, strings.Join(names, ", "))
+	}
+	return name, p, nil
+}
+
+// Flags returns the values the profile sets by flag name.
+func (p *Profile) Flags() map[string][]string {
+	flags := make(map[string][]string)
+	str := func(name, v string) {
+		if v != "" {
+			flags[name] = []string{v}
+		}
+	}
+	num := func(name string, v int) {
+		if v != 0 {
+			flags[name] = []string{strconv.Itoa(v)}
+		}
+	}
+	str("dialect", p.Dialect)
+	str("source", p.Source)
+	if len(p.Orm) > 0 {
+		flags["orm"] = p.Orm
+	}
+	str("bench", p.Bench)
+	num("multi", p.Multi)
+	num("max_idle", p.MaxIdle)
+	num("max_conn", p.MaxConn)
+	num("concurrency", p.Concurrency)
+	num("count", p.Count)
+	str("benchtime", p.Benchtime)
+	str("warmup", p.Warmup)
+	str("timeout", p.Timeout)
+	str("total_timeout", p.TotalTimeout)
+	str("schedule", p.Schedule)
+	if p.Outlier != 0 {
+		flags["outlier"] = []string{strconv.FormatFloat(p.Outlier, 'g', -1, 64)}
+	}
+	num("remeasure", p.Remeasure)
+	str("budgets", p.Budgets)
+	if p.Seed != 0 {
+		flags["seed"] = []string{strconv.FormatInt(p.Seed, 10)}
+	}
+	return flags
+}
+
+// RunConfig is the configuration a run resolved from its flags and config
+// file, it's saved with the results so the run can be repeated.
+type RunConfig struct {
+	Config     string                       `json:",omitempty"`
+	Profile    string                       `json:",omitempty"`
+	Flags      map[string]string            `json:",omitempty"`
+	Benchmarks map[string]BenchmarkOverride `json:",omitempty"`
+}
+
+func (c *RunConfig) String() string {
+	s := "Config:"
+	if c.Config != "" {
+		s += " " + c.Config
+	}
+	if c.Profile != "" {
+		s += " profile " + c.Profile
+	}
+	s += "\n"
+
+	var names []string
+	for name := range c.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		s += fmt.Sprintf("  -%s=%s\n", name, c.Flags[name])
+	}
+
+	names = names[:0]
+	for name := range c.Benchmarks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		o := c.Benchmarks[name]
+		s += fmt.Sprintf("  %s: N %d  L %d\n", name, o.N, o.L)
+	}
+	return s
+}
+
+// override applies ORM_OVERRIDES to the n and l of a benchmark, the
+// orm/name entry wins over the name entry.
+func override(brand, name string, n, l int) (int, int) {
+	for _, key := range []string{name, brand + "/" + name} {
+		o, ok := ORM_OVERRIDES[key]
+		if !ok {
+			continue
+		}
+		if o.N > 0 {
+			n = o.N
+		}
+		if o.L > 0 {
+			l = o.L
+		}
+	}
+	return n, l
+}
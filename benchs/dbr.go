@@ -1,110 +1,73 @@
 package benchs
 
 import (
-	"fmt"
+	"context"
 
 	"github.com/gocraft/dbr"
 )
 
-var dbrsession *dbr.Session
+var dbrColumns = []string{"name", "title", "fax", "web", "age", "right", "counter"}
 
 func init() {
-	st := NewSuite("dbr")
-	st.InitF = func() {
-		st.AddBenchmark("Insert", 2000*ORM_MULTI, 0, DbrInsert)
-		st.AddBenchmark("BulkInsert 100 row", 2000*ORM_MULTI, 0, DbrInsertMulti)
-		st.AddBenchmark("Update", 2000*ORM_MULTI, 0, DbrUpdate)
-		st.AddBenchmark("Read", 2000*ORM_MULTI, 0, DbrRead)
-		st.AddBenchmark("MultiRead limit 2000", 2000*ORM_MULTI, 2000, DbrReadSlice)
-
-		conn, _ := dbr.Open("postgres", ORM_SOURCE, nil)
-		sess := conn.NewSession(nil)
-		dbrsession = sess
-	}
+	NewAdapterSuite("dbr", new(dbrAdapter))
 }
 
-func DbrInsert(b *B) {
-	var m *Model
-	wrapExecute(b, func() {
-		initDB()
-		m = NewModel()
-	})
+type dbrAdapter struct {
+	sess *dbr.Session
+}
 
-	for i := 0; i < b.N; i++ {
-		m.Id = 0
-		if _, err := dbrsession.InsertInto("models").Columns("name", "title", "fax", "web", "age", "right", "counter").Record(m).Exec(); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
+func (d *dbrAdapter) Connect() error {
+	conn, err := dbr.Open(dialect.DriverName, ORM_SOURCE, nil)
+	if err != nil {
+		return err
 	}
+	conn.SetMaxIdleConns(ORM_MAX_IDLE)
+	conn.SetMaxOpenConns(ORM_MAX_CONN)
+	d.sess = conn.NewSession(nil)
+	return nil
 }
 
-func DbrInsertMulti(b *B) {
-	panic(fmt.Errorf("Don't support bulk insert"))
+// InsertOne fetches the id itself, dbr only sets an int64 Id of the
+// record.
+func (d *dbrAdapter) InsertOne(ctx context.Context, m *Model) error {
+	stmt := d.sess.InsertInto("models").Columns(dbrColumns...).Record(m)
+	if dialect.Returning {
+		return stmt.Returning("id").LoadContext(ctx, &m.Id)
+	}
+	res, err := stmt.ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	m.Id = int(id)
+	return nil
 }
 
-func DbrUpdate(b *B) {
-	var m *Model
-	wrapExecute(b, func() {
-		initDB()
-		m = NewModel()
-		if _, err := dbrsession.InsertInto("models").Columns("name", "title", "fax", "web", "age", "right", "counter").Record(m).Exec(); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	})
-
-	for i := 0; i < b.N; i++ {
-		if _, err := dbrsession.Update("models").
-			Set("name", m.Name).
-			Set("title", m.Title).
-			Set("fax", m.Fax).
-			Set("web", m.Web).
-			Set("age", m.Age).
-			Set("right", m.Right).
-			Set("counter", m.Counter).Exec(); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	}
+func (d *dbrAdapter) InsertMany(ctx context.Context, ms []*Model) error {
+	return NotSupported("Don't support bulk insert")
 }
 
-func DbrRead(b *B) {
-	var m *Model
-	wrapExecute(b, func() {
-		initDB()
-		m = NewModel()
-		if _, err := dbrsession.InsertInto("models").Columns("name", "title", "fax", "web", "age", "right", "counter").Record(m).Exec(); err != nil {
-			fmt.Printf("insert before read err: %v\n", err)
-			b.FailNow()
-		}
-	})
+func (d *dbrAdapter) UpdateByPK(ctx context.Context, m *Model) error {
+	_, err := d.sess.Update("models").
+		Set("name", m.Name).
+		Set("title", m.Title).
+		Set("fax", m.Fax).
+		Set("web", m.Web).
+		Set("age", m.Age).
+		Set("right", m.Right).
+		Set("counter", m.Counter).
+		Where("id = ?", m.Id).ExecContext(ctx)
+	return err
+}
 
-	for i := 0; i < b.N; i++ {
-		if _, err := dbrsession.Select("*").From("models").Where("id = ?", m.Id).Load(&m); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	}
+func (d *dbrAdapter) GetByPK(ctx context.Context, id int, m *Model) error {
+	return d.sess.Select("*").From("models").Where("id = ?", id).LoadOneContext(ctx, m)
 }
 
-func DbrReadSlice(b *B) {
-	var m *Model
-	wrapExecute(b, func() {
-		initDB()
-		m = NewModel()
-		for i := 0; i < b.L; i++ {
-			if _, err := dbrsession.InsertInto("models").Columns("name", "title", "fax", "web", "age", "right", "counter").Record(m).Exec(); err != nil {
-				fmt.Println(err)
-				b.FailNow()
-			}
-		}
-	})
-	for i := 0; i < b.N; i++ {
-		var m []Model
-		if _, err := dbrsession.Select("*").From("models").Where("id > ?", 0).Limit(uint64(b.L)).Load(&m); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	}
+func (d *dbrAdapter) FindRange(ctx context.Context, minID, limit int) (int, error) {
+	var models []Model
+	return d.sess.Select("*").From("models").Where("id > ?", minID).OrderBy("id").Limit(uint64(limit)).LoadContext(ctx, &models)
 }
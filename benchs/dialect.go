@@ -0,0 +1,139 @@
+package benchs
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+)
+
+// Dialect holds everything that differs between the databases a suite can
+// run against: driver names, SQL flavour and the default connection source.
+type Dialect struct {
+	Name string
+	// DriverName is the database/sql driver, also understood by gorm, xorm,
+	// beego orm and dbr.
+	DriverName string
+	// ZormDBType is the DBType zorm expects for this driver.
+	ZormDBType string
+	// DefaultSource is used when -source is not given.
+	DefaultSource string
+	// Returning reports whether INSERT ... RETURNING is used to fetch the
+	// new id, pq does not support the LastInsertId method.
+	Returning bool
+	// Schema recreates the models table.
+	Schema []string
+	// SettingsSQL returns name, value rows of the server settings that
+	// matter most for the results, they're recorded with the environment.
+	SettingsSQL string
+
+	numbered  bool
+	quoteChar string
+}
+
+// Quote quotes an identifier, needed for reserved words such as "right".
+func (d *Dialect) Quote(ident string) string {
+	return d.quoteChar + ident + d.quoteChar
+}
+
+// Rebind replaces the ? placeholders of query with the dialect's style.
+func (d *Dialect) Rebind(query string) string {
+	if !d.numbered {
+		return query
+	}
+	var buf strings.Builder
+	n := 0
+	for _, c := range query {
+		if c == '?' {
+			n++
+			buf.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		buf.WriteRune(c)
+	}
+	return buf.String()
+}
+
+var dialects = map[string]*Dialect{
+	"postgres": {
+		Name:          "postgres",
+		DriverName:    "postgres",
+		ZormDBType:    "postgresql",
+		DefaultSource: "host=127.0.0.1 port=5432 user=postgres password=root123456 dbname=test sslmode=disable",
+		Returning:     true,
+		Schema: []string{
+			`DROP TABLE IF EXISTS models;`,
+			`CREATE TABLE models (
+			id SERIAL NOT NULL,
+			name text NOT NULL,
+			title text NOT NULL,
+			fax text NOT NULL,
+			web text NOT NULL,
+			age integer NOT NULL,
+			"right" boolean NOT NULL,
+			counter bigint NOT NULL,
+			CONSTRAINT models_pkey PRIMARY KEY (id)
+			) WITH (OIDS=FALSE);`,
+		},
+		SettingsSQL: `SELECT name, current_setting(name) FROM pg_settings WHERE name IN
+			('max_connections', 'shared_buffers', 'work_mem', 'fsync', 'synchronous_commit',
+			'default_transaction_isolation', 'max_wal_size') ORDER BY name`,
+		numbered:  true,
+		quoteChar: `"`,
+	},
+	"mysql": {
+		Name:          "mysql",
+		DriverName:    "mysql",
+		ZormDBType:    "mysql",
+		DefaultSource: "root:root123456@(127.0.0.1:3306)/test?charset=utf8&parseTime=True&loc=Local",
+		Schema: []string{
+			"DROP TABLE IF EXISTS `models`",
+			"CREATE TABLE `models` (" +
+				"`id` int(11) NOT NULL AUTO_INCREMENT," +
+				"`name` varchar(255) NOT NULL," +
+				"`title` varchar(255) NOT NULL," +
+				"`fax` varchar(255) NOT NULL," +
+				"`web` varchar(255) NOT NULL," +
+				"`age` int(11) NOT NULL," +
+				"`right` tinyint(1) NOT NULL," +
+				"`counter` bigint(20) NOT NULL," +
+				"PRIMARY KEY (`id`)" +
+				") ENGINE=`INNODB` DEFAULT CHARACTER SET utf8 COLLATE utf8_general_ci",
+		},
+		SettingsSQL: "SHOW VARIABLES WHERE Variable_name IN " +
+			"('max_connections', 'innodb_buffer_pool_size', 'innodb_flush_log_at_trx_commit', " +
+			"'sync_binlog', 'transaction_isolation', 'tx_isolation', 'query_cache_type')",
+		quoteChar: "`",
+	},
+}
+
+// DialectNames lists the supported dialects in a stable order.
+func DialectNames() []string {
+	names := make([]string, 0, len(dialects))
+	for name := range dialects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// dialect is the dialect selected by UseDialect.
+var dialect *Dialect
+
+// UseDialect selects the database every suite runs against.
+func UseDialect(name string) error {
+	d, ok := dialects[name]
+	if !ok {
+		return fmt.Errorf("unknown dialect %s, expected one of %s", name, strings.Join(DialectNames(), ", "))
+	}
+	dialect = d
+	return nil
+}
+
+// CurrentDialect returns the dialect selected by UseDialect.
+func CurrentDialect() *Dialect {
+	return dialect
+}
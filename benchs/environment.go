@@ -0,0 +1,230 @@
+package benchs
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment is the machine, database and code a run measured, it's
+// captured at the start of a run and saved with the results.
+type Environment struct {
+	GoVersion  string
+	GOOS       string
+	GOARCH     string
+	GOMAXPROCS int
+	NumCPU     int
+	// CPU is the model name from /proc/cpuinfo.
+	CPU string `json:",omitempty"`
+	// MemTotal is the physical memory in bytes from /proc/meminfo.
+	MemTotal uint64 `json:",omitempty"`
+
+	// DBVersion is what the server answers to SELECT version().
+	DBVersion  string            `json:",omitempty"`
+	DBSettings map[string]string `json:",omitempty"`
+	// DBError tells why the database couldn't be asked.
+	DBError string `json:",omitempty"`
+
+	// Modules maps the module paths the binary was built with to their
+	// versions.
+	Modules map[string]string `json:",omitempty"`
+	// Commit is the git commit of the harness, Modified whether its
+	// working tree had changes.
+	Commit   string `json:",omitempty"`
+	Modified bool   `json:",omitempty"`
+}
+
+// envModules are the modules String prints, the ORMs and drivers.
+var envModules = []string{
+	"github.com/jinzhu/gorm",
+	"xorm.io/xorm",
+	"gitee.com/chunanyong/zorm",
+	"github.com/astaxie/beego",
+	"github.com/gocraft/dbr",
+	"github.com/jmoiron/sqlx",
+	"github.com/go-pg/pg",
+	"github.com/lib/pq",
+	"github.com/go-sql-driver/mysql",
+}
+
+// environment is the fingerprint of the run, captured or restored.
+var environment *Environment
+
+// dbQueryTimeout limits the queries asking the server for its version
+// and settings.
+const dbQueryTimeout = 5 * time.Second
+
+// CaptureEnvironment records the environment of this run for the report
+// and the results. The database is asked with ORM_SOURCE, failing to
+// reach it is recorded in DBError.
+func CaptureEnvironment() *Environment {
+	e := &Environment{
+		GoVersion:  runtime.Version(),
+		GOOS:       runtime.GOOS,
+		GOARCH:     runtime.GOARCH,
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		NumCPU:     runtime.NumCPU(),
+		CPU:        procField("/proc/cpuinfo", "model name"),
+	}
+	if mem := procField("/proc/meminfo", "MemTotal"); mem != "" {
+		kb, _ := strconv.ParseUint(strings.TrimSuffix(mem, " kB"), 10, 64)
+		e.MemTotal = kb * 1024
+	}
+	if dialect != nil {
+		if err := e.captureDB(); err != nil {
+			e.DBError = err.Error()
+		}
+	}
+	e.captureBuild()
+	environment = e
+	return e
+}
+
+// procField returns the value of the first line of a /proc file starting
+// with name, empty if there's none.
+func procField(path, name string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		i := strings.Index(line, ":")
+		if i < 0 || strings.TrimSpace(line[:i]) != name {
+			continue
+		}
+		return strings.TrimSpace(line[i+1:])
+	}
+	return ""
+}
+
+func (e *Environment) captureDB() error {
+	db, err := sql.Open(dialect.DriverName, ORM_SOURCE)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbQueryTimeout)
+	defer cancel()
+	if err := db.QueryRowContext(ctx, "SELECT version()").Scan(&e.DBVersion); err != nil {
+		return err
+	}
+	if dialect.SettingsSQL == "" {
+		return nil
+	}
+	rows, err := db.QueryContext(ctx, dialect.SettingsSQL)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	e.DBSettings = make(map[string]string)
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return err
+		}
+		e.DBSettings[name] = value
+	}
+	return rows.Err()
+}
+
+// captureBuild reads the module versions from the build info, go run
+// doesn't stamp them, then they're read from vendor/modules.txt. The
+// commit is asked from git.
+func (e *Environment) captureBuild() {
+	e.Modules = make(map[string]string)
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, m := range bi.Deps {
+			if m.Replace != nil {
+				m = m.Replace
+			}
+			e.Modules[m.Path] = m.Version
+		}
+	}
+	if len(e.Modules) == 0 {
+		readVendorModules(e.Modules)
+	}
+	if out, err := exec.Command("git", "rev-parse", "HEAD").Output(); err == nil {
+		e.Commit = strings.TrimSpace(string(out))
+		out, err = exec.Command("git", "status", "--porcelain", "--untracked-files=no").Output()
+		e.Modified = err == nil && len(out) > 0
+	}
+}
+
+// readVendorModules adds the "# path version" lines of
+// vendor/modules.txt to modules.
+func readVendorModules(modules map[string]string) {
+	f, err := os.Open("vendor/modules.txt")
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) >= 3 && fields[0] == "#" {
+			// replaced modules read "# path version => path version"
+			modules[fields[1]] = fields[len(fields)-1]
+		}
+	}
+}
+
+func (e *Environment) String() string {
+	s := "Environment:\n"
+	s += fmt.Sprintf("  %s %s/%s GOMAXPROCS %d, %d CPUs\n", e.GoVersion, e.GOOS, e.GOARCH, e.GOMAXPROCS, e.NumCPU)
+	if e.CPU != "" {
+		s += "  CPU: " + e.CPU + "\n"
+	}
+	if e.MemTotal > 0 {
+		s += fmt.Sprintf("  memory: %.1f GiB\n", float64(e.MemTotal)/(1<<30))
+	}
+
+	switch {
+	case e.DBError != "":
+		s += "  database: unknown, " + e.DBError + "\n"
+	case e.DBVersion != "":
+		s += "  database: " + e.DBVersion + "\n"
+	}
+	if len(e.DBSettings) > 0 {
+		var names []string
+		for name := range e.DBSettings {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		var settings []string
+		for _, name := range names {
+			settings = append(settings, name+"="+e.DBSettings[name])
+		}
+		s += "    " + strings.Join(settings, " ") + "\n"
+	}
+
+	var modules []string
+	for _, path := range envModules {
+		if v, ok := e.Modules[path]; ok {
+			modules = append(modules, path[strings.LastIndex(path, "/")+1:]+" "+v)
+		}
+	}
+	if len(modules) > 0 {
+		s += "  modules: " + strings.Join(modules, ", ") + "\n"
+	}
+	if e.Commit != "" {
+		s += "  commit: " + e.Commit
+		if e.Modified {
+			s += " (modified)"
+		}
+		s += "\n"
+	}
+	return s
+}
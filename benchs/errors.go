@@ -0,0 +1,62 @@
+package benchs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/go-pg/pg"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// BenchmarkError describes the first error a benchmark failed with.
+type BenchmarkError struct {
+	Msg string
+	// Iteration is the index of the operation that failed, -1 if the
+	// benchmark failed outside of RunParallel, e.g. during its setup.
+	Iteration int
+	// Type is the Go type of the error, e.g. *pq.Error.
+	Type string
+	// Code is the server error code when the driver exposes one:
+	// the SQLSTATE for postgres, the error number for mysql.
+	Code string
+}
+
+func (e *BenchmarkError) String() string {
+	s := e.Msg
+	if e.Iteration >= 0 {
+		s += fmt.Sprintf(" (iteration %d", e.Iteration)
+	} else {
+		s += " (setup"
+	}
+	if len(e.Type) > 0 {
+		s += ", " + e.Type
+	}
+	if len(e.Code) > 0 {
+		s += " code " + e.Code
+	}
+	return s + ")"
+}
+
+// newBenchmarkError fills in the driver specific parts of err, looking
+// through wrapped errors as zorm wraps the driver errors.
+func newBenchmarkError(err error, iteration int) *BenchmarkError {
+	e := &BenchmarkError{Msg: err.Error(), Iteration: iteration, Type: fmt.Sprintf("%T", err)}
+
+	var pqErr *pq.Error
+	var myErr *mysql.MySQLError
+	var pgErr pg.Error
+	switch {
+	case errors.As(err, &pqErr):
+		e.Type = fmt.Sprintf("%T", pqErr)
+		e.Code = string(pqErr.Code)
+	case errors.As(err, &myErr):
+		e.Type = fmt.Sprintf("%T", myErr)
+		e.Code = strconv.Itoa(int(myErr.Number))
+	case errors.As(err, &pgErr):
+		e.Type = fmt.Sprintf("%T", pgErr)
+		e.Code = pgErr.Field('C')
+	}
+	return e
+}
@@ -1,113 +1,49 @@
 package benchs
 
 import (
-	"fmt"
+	"context"
 
 	"github.com/jinzhu/gorm"
 )
 
-var gormdb *gorm.DB
-
 func init() {
-	st := NewSuite("gorm")
-	st.InitF = func() {
-		st.AddBenchmark("Insert", 2000*ORM_MULTI, 0, GormInsert)
-		st.AddBenchmark("BulkInsert 100 row", 2000*ORM_MULTI, 0, GormInsertMulti)
-		st.AddBenchmark("Update", 2000*ORM_MULTI, 0, GormUpdate)
-		st.AddBenchmark("Read", 2000*ORM_MULTI, 0, GormRead)
-		st.AddBenchmark("MultiRead limit 2000", 2000*ORM_MULTI, 2000, GormReadSlice)
-
-		conn, err := gorm.Open("postgres", ORM_SOURCE)
-		if err != nil {
-			fmt.Println(err)
-		}
-		gormdb = conn
-	}
+	NewAdapterSuite("gorm", new(gormAdapter))
 }
 
-func GormInsert(b *B) {
-	var m *Model
-	wrapExecute(b, func() {
-		initDB()
-		m = NewModel()
-	})
+// gormAdapter uses gorm v1, it has no context support.
+type gormAdapter struct {
+	db *gorm.DB
+}
 
-	for i := 0; i < b.N; i++ {
-		m.Id = 0
-		d := gormdb.Create(&m)
-		if d.Error != nil {
-			fmt.Println(d.Error)
-			b.FailNow()
-		}
+func (g *gormAdapter) Connect() error {
+	conn, err := gorm.Open(dialect.DriverName, ORM_SOURCE)
+	if err != nil {
+		return err
 	}
+	conn.DB().SetMaxIdleConns(ORM_MAX_IDLE)
+	conn.DB().SetMaxOpenConns(ORM_MAX_CONN)
+	g.db = conn
+	return nil
 }
 
-func GormInsertMulti(b *B) {
-	panic(fmt.Errorf("Don't support bulk insert - https://github.com/jinzhu/gorm/issues/255"))
+func (g *gormAdapter) InsertOne(ctx context.Context, m *Model) error {
+	return g.db.Create(m).Error
 }
 
-func GormUpdate(b *B) {
-	var m *Model
-	wrapExecute(b, func() {
-		initDB()
-		m = NewModel()
-		d := gormdb.Create(&m)
-		if d.Error != nil {
-			fmt.Println(d.Error)
-			b.FailNow()
-		}
-	})
-
-	for i := 0; i < b.N; i++ {
-		d := gormdb.Save(&m)
-		if d.Error != nil {
-			fmt.Println(d.Error)
-			b.FailNow()
-		}
-	}
+func (g *gormAdapter) InsertMany(ctx context.Context, ms []*Model) error {
+	return NotSupported("Don't support bulk insert - https://github.com/jinzhu/gorm/issues/255")
 }
 
-func GormRead(b *B) {
-	var m *Model
-	wrapExecute(b, func() {
-		initDB()
-		m = NewModel()
-		d := gormdb.Create(&m)
-		if d.Error != nil {
-			fmt.Println(d.Error)
-			b.FailNow()
-		}
-	})
-	for i := 0; i < b.N; i++ {
-		d := gormdb.Find(&m)
-		if d.Error != nil {
-			fmt.Println(d.Error)
-			b.FailNow()
-		}
-	}
+func (g *gormAdapter) UpdateByPK(ctx context.Context, m *Model) error {
+	return g.db.Save(m).Error
 }
 
-func GormReadSlice(b *B) {
-	var m *Model
-	wrapExecute(b, func() {
-		initDB()
-		m = NewModel()
-		for i := 0; i < b.L; i++ {
-			m.Id = 0
-			d := gormdb.Create(&m)
-			if d.Error != nil {
-				fmt.Println(d.Error)
-				b.FailNow()
-			}
-		}
-	})
+func (g *gormAdapter) GetByPK(ctx context.Context, id int, m *Model) error {
+	return g.db.Where("id = ?", id).Find(m).Error
+}
 
-	for i := 0; i < b.N; i++ {
-		var models []*Model
-		d := gormdb.Where("id > ?", 0).Limit(b.L).Find(&models)
-		if d.Error != nil {
-			fmt.Println(d.Error)
-			b.FailNow()
-		}
-	}
+func (g *gormAdapter) FindRange(ctx context.Context, minID, limit int) (int, error) {
+	var models []*Model
+	d := g.db.Where("id > ?", minID).Order("id asc").Limit(limit).Find(&models)
+	return len(models), d.Error
 }
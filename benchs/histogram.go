@@ -0,0 +1,136 @@
+package benchs
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/bits"
+	"time"
+)
+
+const (
+	histSubBits = 7
+	histSub     = 1 << histSubBits
+	histBuckets = 64 - histSubBits + 1
+)
+
+// Histogram records latencies in log-linear buckets like HdrHistogram:
+// values below histSub are exact, larger values are grouped by their
+// highest bit and then linearly into histSub/2 steps, so every recorded
+// value is kept with a relative error below 1/64.
+type Histogram struct {
+	counts []uint64
+	total  uint64
+	max    int64
+}
+
+func NewHistogram() *Histogram {
+	return &Histogram{counts: make([]uint64, histBuckets*histSub)}
+}
+
+func histIndex(v uint64) int {
+	if v < histSub {
+		return int(v)
+	}
+	shift := bits.Len64(v) - histSubBits
+	return shift*histSub + int(v>>uint(shift))
+}
+
+// histValue returns the highest value that falls into bucket i.
+func histValue(i int) int64 {
+	shift, sub := i/histSub, i%histSub
+	if shift == 0 {
+		return int64(sub)
+	}
+	return int64((uint64(sub+1) << uint(shift)) - 1)
+}
+
+// Record adds one latency.
+func (h *Histogram) Record(d time.Duration) {
+	v := d.Nanoseconds()
+	if v < 0 {
+		v = 0
+	}
+	h.counts[histIndex(uint64(v))]++
+	h.total++
+	if v > h.max {
+		h.max = v
+	}
+}
+
+// Merge adds all values of o to h.
+func (h *Histogram) Merge(o *Histogram) {
+	for i, c := range o.counts {
+		h.counts[i] += c
+	}
+	h.total += o.total
+	if o.max > h.max {
+		h.max = o.max
+	}
+}
+
+func (h *Histogram) Count() uint64 {
+	return h.total
+}
+
+func (h *Histogram) Max() time.Duration {
+	return time.Duration(h.max)
+}
+
+// Percentile returns the latency below which q (0..100) percent of the
+// recorded values fall.
+func (h *Histogram) Percentile(q float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(q / 100 * float64(h.total)))
+	if rank < 1 {
+		rank = 1
+	}
+	var seen uint64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= rank {
+			v := histValue(i)
+			if v > h.max {
+				v = h.max
+			}
+			return time.Duration(v)
+		}
+	}
+	return time.Duration(h.max)
+}
+
+// histJSON is the saved form of a Histogram, the non-empty buckets as
+// index, count pairs.
+type histJSON struct {
+	Max     int64
+	Buckets [][2]uint64
+}
+
+func (h *Histogram) MarshalJSON() ([]byte, error) {
+	hj := histJSON{Max: h.max, Buckets: [][2]uint64{}}
+	for i, c := range h.counts {
+		if c > 0 {
+			hj.Buckets = append(hj.Buckets, [2]uint64{uint64(i), c})
+		}
+	}
+	return json.Marshal(hj)
+}
+
+func (h *Histogram) UnmarshalJSON(data []byte) error {
+	var hj histJSON
+	if err := json.Unmarshal(data, &hj); err != nil {
+		return err
+	}
+	*h = *NewHistogram()
+	h.max = hj.Max
+	for _, b := range hj.Buckets {
+		if b[0] >= uint64(len(h.counts)) {
+			return fmt.Errorf("histogram bucket %d out of range", b[0])
+		}
+		h.counts[b[0]] += b[1]
+		h.total += b[1]
+	}
+	return nil
+}
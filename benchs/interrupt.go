@@ -0,0 +1,66 @@
+package benchs
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+var (
+	interruptMu sync.Mutex
+	interrupts  int
+	// aborted is closed by the second Interrupt, it aborts the running
+	// benchmark.
+	aborted = make(chan struct{})
+)
+
+// Interrupt is called on SIGINT or SIGTERM and returns how often it was
+// called. The first time no further benchmark starts, the running one
+// finishes. The second time the running one is aborted like on timeout.
+func Interrupt() int {
+	interruptMu.Lock()
+	defer interruptMu.Unlock()
+	interrupts++
+	if interrupts == 2 {
+		close(aborted)
+	}
+	return interrupts
+}
+
+// Interrupted reports whether Interrupt was called.
+func Interrupted() bool {
+	interruptMu.Lock()
+	defer interruptMu.Unlock()
+	return interrupts > 0
+}
+
+var checkpointErr sync.Once
+
+// checkpoint saves the results so far to ORM_CHECKPOINT, so an interrupted
+// or crashed run can be resumed.
+func checkpoint() {
+	if err := Checkpoint(); err != nil {
+		checkpointErr.Do(func() {
+			fmt.Fprintln(os.Stderr, "checkpoint:", err)
+		})
+	}
+}
+
+// Checkpoint writes the results of the suites to ORM_CHECKPOINT if set.
+func Checkpoint() error {
+	if ORM_CHECKPOINT == "" {
+		return nil
+	}
+	// written next to it first, a crash mid write keeps the old one
+	tmp := ORM_CHECKPOINT + ".tmp"
+	if err := WriteResults(tmp, CollectResults()); err != nil {
+		return err
+	}
+	return os.Rename(tmp, ORM_CHECKPOINT)
+}
+
+// Remaining returns how many of count repetitions the saved benchmark has
+// yet to run, like a resumed run counts them.
+func (sb SavedBenchmark) Remaining(count int) int {
+	return remaining(sb.Result, len(sb.Samples), count)
+}
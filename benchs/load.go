@@ -0,0 +1,102 @@
+package benchs
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// busyLoad is the 1 minute load average per CPU, and busySteal the
+// percentage of CPU time stolen by the hypervisor, above which the
+// machine is too busy to measure reliably.
+const (
+	busyLoad  = 1.0
+	busySteal = 5.0
+)
+
+// Load is the system load sampled before a benchmark run.
+type Load struct {
+	// Load1 is the 1 minute load average from /proc/loadavg.
+	Load1 float64
+	// Steal is the percentage of CPU time stolen since the previous
+	// sample, from /proc/stat.
+	Steal float64
+}
+
+// Busy reports whether the machine was too busy for a reliable
+// measurement.
+func (l *Load) Busy() bool {
+	return l != nil && (l.Load1 > busyLoad*float64(runtime.NumCPU()) || l.Steal > busySteal)
+}
+
+func (l *Load) String() string {
+	return fmt.Sprintf("load %.2f on %d CPUs, steal %.1f%%", l.Load1, runtime.NumCPU(), l.Steal)
+}
+
+var lastCPU struct {
+	sync.Mutex
+	steal, total uint64
+}
+
+// sampleLoad returns the current load, nil where /proc isn't available.
+func sampleLoad() *Load {
+	data, err := ioutil.ReadFile("/proc/loadavg")
+	if err != nil {
+		return nil
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return nil
+	}
+	l := new(Load)
+	l.Load1, _ = strconv.ParseFloat(fields[0], 64)
+
+	// cpu user nice system idle iowait irq softirq steal guest guest_nice
+	cpu := strings.Fields(procLine("/proc/stat", "cpu "))
+	if len(cpu) < 9 {
+		return l
+	}
+	var steal, total uint64
+	for i, f := range cpu[1:] {
+		v, _ := strconv.ParseUint(f, 10, 64)
+		// guest time is counted in user time already
+		if i < 8 {
+			total += v
+		}
+		if i == 7 {
+			steal = v
+		}
+	}
+	lastCPU.Lock()
+	if total > lastCPU.total {
+		l.Steal = float64(steal-lastCPU.steal) / float64(total-lastCPU.total) * 100
+	}
+	lastCPU.steal, lastCPU.total = steal, total
+	lastCPU.Unlock()
+	return l
+}
+
+// procLine returns the first line of a /proc file starting with prefix.
+func procLine(path, prefix string) string {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, prefix) {
+			return line
+		}
+	}
+	return ""
+}
+
+// warnBusy prints a warning when the machine is busy before b runs.
+func warnBusy(b *B, l *Load) {
+	if l.Busy() {
+		fmt.Fprintf(os.Stderr, "warning: busy machine before %s %s: %v\n", b.Brand, b.Name, l)
+	}
+}
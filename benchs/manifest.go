@@ -0,0 +1,208 @@
+package benchs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Manifest is the plan of a run, it's written next to the results so
+// the run can be repeated with the rerun command.
+type Manifest struct {
+	Date time.Time
+	// Config holds the value of every flag, the source redacted.
+	Config  *RunConfig
+	Dialect string
+	// Source is the DSN the run connected to with its password redacted.
+	Source string
+	// Seed seeds the suite order and the interleaved schedule.
+	Seed int64
+	// Order is the order of the suites after the seeded shuffle.
+	Order      []string
+	Benchmarks []PlannedBenchmark
+	Env        *Environment `json:",omitempty"`
+}
+
+// PlannedBenchmark is a benchmark the run registered with its N and L.
+type PlannedBenchmark struct {
+	Brand string
+	Name  string
+	N     int
+	L     int
+	// Ran is the N each sample ran, calibrated by a -benchtime duration,
+	// recorded after the run.
+	Ran []int `json:",omitempty"`
+}
+
+// NewManifest records the plan of a run of the suites in order, call it
+// after PrepareBenchmarks.
+func NewManifest(config *RunConfig, seed int64, order []string) *Manifest {
+	m := &Manifest{
+		Date:   time.Now(),
+		Config: config,
+		Seed:   seed,
+		Order:  order,
+		Env:    environment,
+	}
+	if dialect != nil {
+		m.Dialect = dialect.Name
+		m.Source = dialect.RedactSource(ORM_SOURCE)
+	}
+	for _, name := range order {
+		for _, sb := range SuiteBenchmarks(name) {
+			m.Benchmarks = append(m.Benchmarks, PlannedBenchmark{Brand: name, Name: sb.Name, N: sb.N, L: sb.L})
+		}
+	}
+	return m
+}
+
+// Overrides returns the N and L of every planned benchmark by orm/name,
+// for ORM_OVERRIDES.
+func (m *Manifest) Overrides() map[string]BenchmarkOverride {
+	o := make(map[string]BenchmarkOverride)
+	for _, pb := range m.Benchmarks {
+		o[pb.Brand+"/"+pb.Name] = BenchmarkOverride{N: pb.N, L: pb.L}
+	}
+	return o
+}
+
+// Pinned returns the N each sample ran by orm/name, for ORM_PINNED.
+func (m *Manifest) Pinned() map[string][]int {
+	p := make(map[string][]int)
+	for _, pb := range m.Benchmarks {
+		if len(pb.Ran) > 0 {
+			p[pb.Brand+"/"+pb.Name] = pb.Ran
+		}
+	}
+	return p
+}
+
+// RecordRuns records the N each sample of r ran in the benchmarks of m.
+func (m *Manifest) RecordRuns(r *Results) {
+	for i := range m.Benchmarks {
+		pb := &m.Benchmarks[i]
+		pb.Ran = nil
+		sb, ok := r.Find(pb.Brand, pb.Name)
+		if !ok {
+			continue
+		}
+		for _, s := range sb.Samples {
+			pb.Ran = append(pb.Ran, s.N)
+		}
+	}
+}
+
+// Check reports how the plan cur differs from m, e.g. when a suite is
+// no longer compiled in or was registered in a different position.
+func (m *Manifest) Check(cur *Manifest) error {
+	if strings.Join(cur.Order, " ") != strings.Join(m.Order, " ") {
+		return fmt.Errorf("suite order %v, the manifest has %v", cur.Order, m.Order)
+	}
+	if len(cur.Benchmarks) != len(m.Benchmarks) {
+		return fmt.Errorf("%d benchmarks, the manifest has %d", len(cur.Benchmarks), len(m.Benchmarks))
+	}
+	for i, pb := range m.Benchmarks {
+		c := cur.Benchmarks[i]
+		if c.Brand != pb.Brand || c.Name != pb.Name || c.N != pb.N || c.L != pb.L {
+			return fmt.Errorf("benchmark %d is %s %s N %d L %d, the manifest has %s %s N %d L %d",
+				i, c.Brand, c.Name, c.N, c.L, pb.Brand, pb.Name, pb.N, pb.L)
+		}
+	}
+	return nil
+}
+
+// Unredact returns the source to rerun m with: source if given, the
+// default source of the dialect if m ran with it, m.Source if it had no
+// password.
+func (m *Manifest) Unredact(source string) (string, error) {
+	if source != "" {
+		return source, nil
+	}
+	d, ok := dialects[m.Dialect]
+	if !ok {
+		return "", fmt.Errorf("unknown dialect %s", m.Dialect)
+	}
+	if m.Source == d.RedactSource(d.DefaultSource) {
+		return d.DefaultSource, nil
+	}
+	if !strings.Contains(m.Source, redacted) {
+		return m.Source, nil
+	}
+	return "", fmt.Errorf("the password of source %s is redacted, pass it with -source", m.Source)
+}
+
+func WriteManifest(path string, m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+func ReadManifest(path string) (*Manifest, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m := new(Manifest)
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return m, nil
+}
+
+// ManifestPath returns where the manifest of the results saved to out
+// goes, results.json gets results.manifest.json.
+func ManifestPath(out string) string {
+	if out == "" {
+		return ""
+	}
+	return strings.TrimSuffix(out, ".json") + ".manifest.json"
+}
+
+// redacted replaces passwords in sources.
+const redacted = "xxxxx"
+
+var pqPassword = regexp.MustCompile(`(^|\s)password=('[^']*'|\S*)`)
+
+// RedactSource replaces the password in source, a URL, a lib/pq
+// key=value list or a mysql DSN, so it can be printed and saved.
+func (d *Dialect) RedactSource(source string) string {
+	if strings.Contains(source, "://") {
+		u, err := url.Parse(source)
+		if err != nil || u.User == nil {
+			return source
+		}
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redacted)
+		}
+		return u.String()
+	}
+	if d.Name != "mysql" {
+		return pqPassword.ReplaceAllString(source, "${1}password="+redacted)
+	}
+
+	// user:password@net(addr)/dbname?params, the password may contain
+	// @ and the params /
+	base := source
+	if i := strings.Index(base, "?"); i >= 0 {
+		base = base[:i]
+	}
+	slash := strings.LastIndex(base, "/")
+	if slash < 0 {
+		return source
+	}
+	at := strings.LastIndex(base[:slash], "@")
+	if at < 0 {
+		return source
+	}
+	colon := strings.Index(source[:at], ":")
+	if colon < 0 {
+		return source
+	}
+	return source[:colon+1] + redacted + source[at:]
+}
@@ -0,0 +1,112 @@
+package benchs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRedactSource(t *testing.T) {
+	tests := []struct {
+		dialect, source, want string
+	}{
+		{"postgres", "host=127.0.0.1 port=5432 user=postgres password=root123456 dbname=test", "host=127.0.0.1 port=5432 user=postgres password=xxxxx dbname=test"},
+		{"postgres", "password='a b' user=u", "password=xxxxx user=u"},
+		{"postgres", "host=h user=u", "host=h user=u"},
+		{"postgres", "postgres://u:secret@h:5432/test?sslmode=disable", "postgres://u:xxxxx@h:5432/test?sslmode=disable"},
+		{"postgres", "postgres://u@h/test", "postgres://u@h/test"},
+		{"mysql", "root:root123456@(127.0.0.1:3306)/test?charset=utf8", "root:xxxxx@(127.0.0.1:3306)/test?charset=utf8"},
+		{"mysql", "u:p@ss/w@tcp(h)/test?loc=Asia/Shanghai", "u:xxxxx@tcp(h)/test?loc=Asia/Shanghai"},
+		{"mysql", "u@tcp(h)/test", "u@tcp(h)/test"},
+	}
+	for _, tt := range tests {
+		if got := dialects[tt.dialect].RedactSource(tt.source); got != tt.want {
+			t.Errorf("%s RedactSource(%q) = %q, want %q", tt.dialect, tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestManifest(t *testing.T) {
+	fakeSuites(t)
+	newFakeSuite("alpha", fakeBench{"Insert", sleepOps(0)}, fakeBench{"Read", sleepOps(0)})
+	newFakeSuite("beta", fakeBench{"Insert", sleepOps(0)})
+	if err := UseDialect("postgres"); err != nil {
+		t.Fatal(err)
+	}
+	oldSource := ORM_SOURCE
+	t.Cleanup(func() { ORM_SOURCE = oldSource })
+	ORM_SOURCE = dialect.DefaultSource
+	PrepareBenchmarks(BrandNames)
+
+	dir, err := ioutil.TempDir("", "manifest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "results.manifest.json")
+	m := NewManifest(&RunConfig{}, 42, []string{"beta", "alpha"})
+	if err := WriteManifest(path, m); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadManifest(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := read.Check(m); err != nil {
+		t.Errorf("Check of the written plan: %v", err)
+	}
+	if len(read.Benchmarks) != 3 || read.Benchmarks[0].Brand != "beta" {
+		t.Errorf("Benchmarks = %+v, want beta's first", read.Benchmarks)
+	}
+	if o := read.Overrides()["alpha/Read"]; o.N != 10 {
+		t.Errorf("alpha/Read override N = %d, want 10", o.N)
+	}
+	if source, err := read.Unredact(""); err != nil || source != dialect.DefaultSource {
+		t.Errorf("Unredact = %q, %v, want the default source", source, err)
+	}
+
+	if err := read.Check(NewManifest(&RunConfig{}, 42, []string{"alpha", "beta"})); err == nil {
+		t.Error("Check of another order passed")
+	}
+	read.Benchmarks[1].N++
+	if err := read.Check(m); err == nil {
+		t.Error("Check of another N passed")
+	}
+}
+
+func TestManifestRecordRuns(t *testing.T) {
+	fakeSuites(t)
+	ORM_COUNT = 2
+	ORM_BENCHTIME = BenchTime{D: time.Millisecond}
+	newFakeSuite("alpha", fakeBench{"Insert", sleepOps(10 * time.Microsecond)})
+	PrepareBenchmarks(BrandNames)
+	m := NewManifest(&RunConfig{}, 42, []string{"alpha"})
+	RunBenchmark("alpha")
+	m.RecordRuns(CollectResults())
+	pb := m.Benchmarks[0]
+	if pb.N != 10 || len(pb.Ran) != 2 {
+		t.Fatalf("N %d, Ran %v, want the registered N and the N of 2 samples", pb.N, pb.Ran)
+	}
+	sb := SuiteBenchmarks("alpha")[0]
+	for i, s := range sb.Samples {
+		if pb.Ran[i] != s.N {
+			t.Errorf("Ran[%d] = %d, the sample ran %d", i, pb.Ran[i], s.N)
+		}
+	}
+
+	// a replay runs the pinned N whatever the duration
+	m.Benchmarks[0].Ran = []int{3, 5}
+	fakeSuites(t)
+	ORM_COUNT = 2
+	ORM_BENCHTIME = BenchTime{D: time.Millisecond}
+	ORM_PINNED = m.Pinned()
+	newFakeSuite("alpha", fakeBench{"Insert", sleepOps(0)})
+	PrepareBenchmarks(BrandNames)
+	RunBenchmark("alpha")
+	sb = SuiteBenchmarks("alpha")[0]
+	if len(sb.Samples) != 2 || sb.Samples[0].N != 3 || sb.Samples[1].N != 5 {
+		t.Errorf("replayed samples %v, want N 3 and 5", sb.Samples)
+	}
+}
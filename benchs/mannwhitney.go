@@ -0,0 +1,117 @@
+package benchs
+
+import (
+	"math"
+	"sort"
+)
+
+// exactMaxSamples is the largest sample size MannWhitneyU computes the
+// exact distribution of U for, larger ones use the normal approximation.
+const exactMaxSamples = 50
+
+// MannWhitneyU returns the two sided p-value of the Mann-Whitney U test
+// of whether x and y come from the same distribution. Without ties and
+// for small samples it's exact, otherwise it's the normal approximation
+// with tie and continuity corrections.
+func MannWhitneyU(x, y []float64) float64 {
+	n1, n2 := len(x), len(y)
+	if n1 == 0 || n2 == 0 {
+		return 1
+	}
+
+	type value struct {
+		v     float64
+		fromX bool
+	}
+	all := make([]value, 0, n1+n2)
+	for _, v := range x {
+		all = append(all, value{v, true})
+	}
+	for _, v := range y {
+		all = append(all, value{v, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].v < all[j].v })
+
+	// rank sum of x, ties get the mean of their ranks
+	var r1, tieSum float64
+	ties := false
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].fromX {
+				r1 += rank
+			}
+		}
+		if t := float64(j - i); t > 1 {
+			ties = true
+			tieSum += t*t*t - t
+		}
+		i = j
+	}
+	u := r1 - float64(n1*(n1+1))/2
+
+	if !ties && n1 <= exactMaxSamples && n2 <= exactMaxSamples {
+		return exactUPValue(u, n1, n2)
+	}
+
+	n := float64(n1 + n2)
+	mu := float64(n1*n2) / 2
+	sigma := math.Sqrt(float64(n1*n2) / 12 * ((n + 1) - tieSum/(n*(n-1))))
+	if sigma == 0 {
+		return 1
+	}
+	z := (math.Abs(u-mu) - 0.5) / sigma
+	if z < 0 {
+		z = 0
+	}
+	return math.Min(1, math.Erfc(z/math.Sqrt2))
+}
+
+// exactUPValue returns the two sided p-value of U for samples of n1 and
+// n2 without ties, counting the orderings giving each U.
+func exactUPValue(u float64, n1, n2 int) float64 {
+	// counts[m][k] orderings of m values of x among the values of y
+	// seen so far with U k, y is added one value at a time
+	maxU := n1 * n2
+	counts := make([][]float64, n1+1)
+	for m := range counts {
+		counts[m] = make([]float64, maxU+1)
+	}
+	for m := 0; m <= n1; m++ {
+		counts[m][0] = 1
+	}
+	for j := 1; j <= n2; j++ {
+		next := make([][]float64, n1+1)
+		for m := range next {
+			next[m] = make([]float64, maxU+1)
+		}
+		next[0][0] = 1
+		for m := 1; m <= n1; m++ {
+			for k := 0; k <= m*j; k++ {
+				// the largest value is from y, or from x and beats
+				// all j values of y
+				next[m][k] = counts[m][k]
+				if k >= j {
+					next[m][k] += next[m-1][k-j]
+				}
+			}
+		}
+		counts = next
+	}
+
+	var total, le, ge float64
+	for k, c := range counts[n1] {
+		total += c
+		if float64(k) <= u {
+			le += c
+		}
+		if float64(k) >= u {
+			ge += c
+		}
+	}
+	return math.Min(1, 2*math.Min(le, ge)/total)
+}
@@ -0,0 +1,99 @@
+package benchs
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// minOutlierDelta is how far from the median, relative to it, a sample
+// must be to be an outlier, so tightly clustered samples with a MAD near
+// zero don't flag a few percent of noise.
+const minOutlierDelta = 0.1
+
+func median(vs []float64) float64 {
+	if len(vs) == 0 {
+		return 0
+	}
+	s := append([]float64(nil), vs...)
+	sort.Float64s(s)
+	if len(s)%2 == 1 {
+		return s[len(s)/2]
+	}
+	return (s[len(s)/2-1] + s[len(s)/2]) / 2
+}
+
+// mad is the median absolute deviation from the median.
+func mad(vs []float64) float64 {
+	m := median(vs)
+	devs := make([]float64, len(vs))
+	for i, v := range vs {
+		devs[i] = math.Abs(v - m)
+	}
+	return median(devs)
+}
+
+// Outliers returns the indexes of the samples whose ns/op has a modified
+// z-score, 0.6745 (x - median) / MAD, beyond threshold. It needs three
+// samples at least.
+func (s Stats) Outliers(threshold float64) (idx []int) {
+	vs := s.NsPerOp()
+	if threshold <= 0 || len(vs) < 3 {
+		return nil
+	}
+	m := median(vs)
+	d := mad(vs)
+	for i, v := range vs {
+		dev := math.Abs(v - m)
+		if dev <= minOutlierDelta*m {
+			continue
+		}
+		if d == 0 || 0.6745*dev/d > threshold {
+			idx = append(idx, i)
+		}
+	}
+	return
+}
+
+// checkOutliers drops the outlier samples once all count repetitions
+// ran, so they're measured again, unless that exceeds ORM_REMEASURE. Then
+// the samples are kept and the benchmark is marked unstable.
+func (b *B) checkOutliers(count int) {
+	if len(b.samples) < count {
+		return
+	}
+	idx := b.Stats().Outliers(ORM_OUTLIER)
+	b.unstable = false
+	if len(idx) == 0 {
+		return
+	}
+	if len(b.outliers)+len(idx) > ORM_REMEASURE {
+		b.unstable = true
+		return
+	}
+	kept := b.samples[:0:0]
+	for i, r := range b.samples {
+		if len(idx) > 0 && idx[0] == i {
+			b.outliers = append(b.outliers, r)
+			idx = idx[1:]
+			continue
+		}
+		kept = append(kept, r)
+	}
+	b.samples = kept
+}
+
+// stability returns the marks MakeReport adds to a benchmark measured
+// more than once.
+func (b *B) stability() (marks string) {
+	if len(b.outliers) > 0 {
+		marks += fmt.Sprintf("  %d re-measured", len(b.outliers))
+	}
+	if b.unstable {
+		marks += "  ! unstable"
+	}
+	if n := b.Stats().Busy(); n > 0 {
+		marks += fmt.Sprintf("  ! busy machine in %d runs", n)
+	}
+	return
+}
@@ -0,0 +1,109 @@
+package benchs
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// samples returns results of one operation taking each of ns.
+func samples(ns ...int) (rs []*BenchmarkResult) {
+	for _, n := range ns {
+		rs = append(rs, &BenchmarkResult{N: 1, T: time.Duration(n), Status: StatusOK})
+	}
+	return
+}
+
+func TestOutliers(t *testing.T) {
+	tests := []struct {
+		ns   []int
+		want []int
+	}{
+		{[]int{100, 300}, nil},
+		{[]int{100, 102, 98, 101, 300}, []int{4}},
+		{[]int{300, 100, 102, 98, 101, 99}, []int{0}},
+		{[]int{100, 100, 100, 103}, nil},
+		{[]int{100, 100, 100, 200}, []int{3}},
+		{[]int{100, 150, 200, 250, 300}, nil},
+		{[]int{30, 100, 102, 98, 101, 300}, []int{0, 5}},
+	}
+	for _, tt := range tests {
+		if got := (Stats{Samples: samples(tt.ns...)}).Outliers(3.5); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Outliers of %v = %v, want %v", tt.ns, got, tt.want)
+		}
+	}
+	if got := (Stats{Samples: samples(100, 100, 100, 900)}).Outliers(0); got != nil {
+		t.Errorf("Outliers with threshold 0 = %v, want none", got)
+	}
+}
+
+func TestCheckOutliers(t *testing.T) {
+	fakeSuites(t)
+	ORM_OUTLIER = 3.5
+	ORM_REMEASURE = 1
+
+	b := &B{samples: samples(100, 101, 400, 99)}
+	b.checkOutliers(5)
+	if len(b.samples) != 4 {
+		t.Fatalf("dropped samples before all 5 ran: %d left", len(b.samples))
+	}
+	b.samples = append(b.samples, samples(100)...)
+	b.checkOutliers(5)
+	if len(b.samples) != 4 || len(b.outliers) != 1 || b.unstable {
+		t.Fatalf("got %d samples, %d outliers, unstable %v, want the 400 dropped", len(b.samples), len(b.outliers), b.unstable)
+	}
+	if b.remaining(5) != 1 {
+		t.Errorf("remaining %d, want the outlier measured again", b.remaining(5))
+	}
+
+	// the budget is spent, the next outlier is kept
+	b.samples = append(b.samples, samples(500)...)
+	b.checkOutliers(5)
+	if len(b.samples) != 5 || !b.unstable {
+		t.Fatalf("got %d samples, unstable %v, want all kept and unstable", len(b.samples), b.unstable)
+	}
+	if !strings.Contains(b.stability(), "! unstable") {
+		t.Errorf("stability() = %q, want the unstable mark", b.stability())
+	}
+}
+
+func TestRemeasureInterleaved(t *testing.T) {
+	fakeSuites(t)
+	ORM_COUNT = 5
+	ORM_OUTLIER = 3.5
+	ORM_REMEASURE = 3
+
+	// the last repetition is 20 times slower, it can only be measured
+	// again after the repetitions of RunInterleaved
+	runs := 0
+	newFakeSuite("alpha", fakeBench{"Read", func(b *B) {
+		runs++
+		d := time.Millisecond
+		if runs == 5 {
+			d *= 20
+		}
+		sleepOps(d)(b)
+	}})
+	PrepareBenchmarks(BrandNames)
+	RunInterleaved(BrandNames, rand.New(rand.NewSource(1)))
+
+	b := benchmarks["alpha"].benchs[0]
+	if len(b.samples) != 5 {
+		t.Errorf("%d samples, want 5", len(b.samples))
+	}
+	// sleeping is noisy, other runs may be dropped too
+	dropped := false
+	for _, r := range b.outliers {
+		dropped = dropped || r.nsPerOp() >= 10*float64(time.Millisecond)
+	}
+	if !dropped {
+		t.Errorf("outliers %v, want the slow run dropped", b.outliers)
+	}
+	for _, r := range b.samples {
+		if r.nsPerOp() >= 10*float64(time.Millisecond) {
+			t.Errorf("slow run %v kept in the samples", r.T)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package benchs
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	calibrationN      = 100000
+	calibrationRounds = 5
+	timerPairs        = 100000
+)
+
+var (
+	calibrateOnce sync.Once
+	// harnessOverhead is what one pb.Next costs in ns/op with
+	// ORM_CONCURRENCY workers, it's subtracted from every result.
+	harnessOverhead float64
+	// timerOverhead is what a StopTimer, StartTimer pair costs in ns,
+	// e.g. around the setup in wrapExecute.
+	timerOverhead float64
+)
+
+// subtractOverhead removes the cost of the harness from T.
+func (r *BenchmarkResult) subtractOverhead(nsPerOp float64) {
+	if nsPerOp <= 0 || r.N <= 0 {
+		return
+	}
+	d := time.Duration(nsPerOp * float64(r.N))
+	if d > r.T {
+		d = r.T
+	}
+	r.T -= d
+	r.Overhead = nsPerOp
+}
+
+// calibrate measures the harness with a benchmark doing nothing in its
+// RunParallel loop, the fastest of a few rounds is its overhead.
+func calibrate() {
+	if !ORM_CALIBRATE {
+		return
+	}
+	best := math.Inf(1)
+	for i := 0; i < calibrationRounds; i++ {
+		b := &B{
+			common: common{
+				signal: make(chan interface{}, 1),
+			},
+			Name: "calibrate",
+			N:    calibrationN,
+			F: func(b *B) {
+				b.runParallel(func(pb *PB) {
+					for pb.Next() {
+					}
+				})
+			},
+		}
+		go b.launch(b.signal)
+		r := (<-b.signal).(*BenchmarkResult)
+		if r.Status == StatusOK && r.nsPerOp() < best {
+			best = r.nsPerOp()
+		}
+	}
+	if !math.IsInf(best, 1) {
+		harnessOverhead = best
+	}
+
+	b := &B{}
+	start := time.Now()
+	for i := 0; i < timerPairs; i++ {
+		b.StopTimer()
+		b.StartTimer()
+	}
+	timerOverhead = float64(time.Since(start).Nanoseconds()) / timerPairs
+}
+
+// overheadReport tells how much of the results was the harness itself.
+func overheadReport() string {
+	lo, hi := math.Inf(1), 0.0
+	for _, s := range benchmarks {
+		for _, b := range s.benchs {
+			if b.result == nil || b.result.Overhead <= 0 {
+				continue
+			}
+			lo = math.Min(lo, b.result.Overhead)
+			hi = math.Max(hi, b.result.Overhead)
+		}
+	}
+	if hi == 0 {
+		return ""
+	}
+	s := fmt.Sprintf("Harness overhead: %.1f ns/op", lo)
+	if hi-lo >= 0.05 {
+		s = fmt.Sprintf("Harness overhead: %.1f - %.1f ns/op", lo, hi)
+	}
+	s += " measured with an empty benchmark, subtracted from the results"
+	if timerOverhead > 0 {
+		s += fmt.Sprintf(", StopTimer+StartTimer %.0f ns", timerOverhead)
+	}
+	return s + "\n"
+}
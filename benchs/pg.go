@@ -1,122 +1,87 @@
 package benchs
 
 import (
-	"fmt"
+	"context"
+	"net"
+	"strings"
 
 	"github.com/go-pg/pg"
 )
 
-var pgdb *pg.DB
-
 func init() {
-	st := NewSuite("pg")
-	st.InitF = func() {
-		st.AddBenchmark("Insert", 2000*ORM_MULTI, 0, PgInsert)
-		st.AddBenchmark("BulkInsert 100 row", 2000*ORM_MULTI, 0, PgInsertMulti)
-		st.AddBenchmark("Update", 2000*ORM_MULTI, 0, PgUpdate)
-		st.AddBenchmark("Read", 2000*ORM_MULTI, 0, PgRead)
-		st.AddBenchmark("MultiRead limit 2000", 2000*ORM_MULTI, 2000, PgReadSlice)
-
-		pgdb = pg.Connect(&pg.Options{
-			Addr:     "127.0.0.1:5432",
-			User:     "postgres",
-			Password: "root123456",
-			Database: "test",
-		})
-	}
+	st := NewAdapterSuite("pg", new(pgAdapter))
+	st.Dialects = []string{"postgres"}
 }
 
-func PgInsert(b *B) {
-	var m *Model
-	wrapExecute(b, func() {
-		initDB()
-		m = NewModel()
-	})
-
-	for i := 0; i < b.N; i++ {
-		m.Id = 0
-		if err := pgdb.Insert(m); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
+// pgOptions converts a lib/pq key=value source into go-pg options.
+func pgOptions(source string) (*pg.Options, error) {
+	if strings.HasPrefix(source, "postgres://") || strings.HasPrefix(source, "postgresql://") {
+		return pg.ParseURL(source)
 	}
-}
-
-func PgInsertMulti(b *B) {
-	var ms []*Model
-	wrapExecute(b, func() {
-		initDB()
-	})
 
-	for i := 0; i < b.N; i++ {
-		ms = make([]*Model, 0, 100)
-		for i := 0; i < 100; i++ {
-			ms = append(ms, NewModel())
+	host, port := "127.0.0.1", "5432"
+	opts := &pg.Options{}
+	for _, kv := range strings.Fields(source) {
+		i := strings.Index(kv, "=")
+		if i < 0 {
+			continue
 		}
-		if err := pgdb.Insert(&ms); err != nil {
-			fmt.Println(err)
-			b.FailNow()
+		v := strings.Trim(kv[i+1:], "'")
+		switch kv[:i] {
+		case "host":
+			host = v
+		case "port":
+			port = v
+		case "user":
+			opts.User = v
+		case "password":
+			opts.Password = v
+		case "dbname":
+			opts.Database = v
 		}
 	}
+	opts.Addr = net.JoinHostPort(host, port)
+	return opts, nil
 }
 
-func PgUpdate(b *B) {
-	var m *Model
-	wrapExecute(b, func() {
-		initDB()
-		m = NewModel()
-		if err := pgdb.Insert(m); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	})
+// pgAdapter uses go-pg, ModelContext costs the same as the Insert, Update
+// and Select shortcuts of pg.DB.
+type pgAdapter struct {
+	db *pg.DB
+}
 
-	for i := 0; i < b.N; i++ {
-		if err := pgdb.Update(m); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
+func (p *pgAdapter) Connect() error {
+	opts, err := pgOptions(ORM_SOURCE)
+	if err != nil {
+		return err
 	}
+	opts.PoolSize = ORM_MAX_CONN
+	p.db = pg.Connect(opts)
+	return nil
 }
 
-func PgRead(b *B) {
-	var m *Model
-	wrapExecute(b, func() {
-		initDB()
-		m = NewModel()
-		if err := pgdb.Insert(m); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	})
+func (p *pgAdapter) InsertOne(ctx context.Context, m *Model) error {
+	_, err := p.db.ModelContext(ctx, m).Insert()
+	return err
+}
 
-	for i := 0; i < b.N; i++ {
-		if err := pgdb.Select(m); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	}
+func (p *pgAdapter) InsertMany(ctx context.Context, ms []*Model) error {
+	_, err := p.db.ModelContext(ctx, &ms).Insert()
+	return err
 }
 
-func PgReadSlice(b *B) {
-	var m *Model
-	wrapExecute(b, func() {
-		initDB()
-		m = NewModel()
-		for i := 0; i < b.L; i++ {
-			m.Id = 0
-			if err := pgdb.Insert(m); err != nil {
-				fmt.Println(err)
-				b.FailNow()
-			}
-		}
-	})
+func (p *pgAdapter) UpdateByPK(ctx context.Context, m *Model) error {
+	_, err := p.db.ModelContext(ctx, m).WherePK().Update()
+	return err
+}
 
-	for i := 0; i < b.N; i++ {
-		var models []*Model
-		if err := pgdb.Model(&models).Where("id > ?", 0).Limit(b.L).Select(); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	}
+func (p *pgAdapter) GetByPK(ctx context.Context, id int, m *Model) error {
+	m.Id = id
+	return p.db.ModelContext(ctx, m).WherePK().Select()
+}
+
+func (p *pgAdapter) FindRange(ctx context.Context, minID, limit int) (int, error) {
+	var models []*Model
+	err := p.db.ModelContext(ctx, &models).Where("id > ?", minID).Order("id").Limit(limit).Select()
+	return len(models), err
 }
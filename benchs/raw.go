@@ -1,239 +1,175 @@
 package benchs
 
 import (
+	"context"
 	"database/sql"
-	"fmt"
-	"strconv"
 	"strings"
+	"sync"
 )
 
-var raw *sql.DB
-
-const (
-	rawInsertBaseSQL   = `INSERT INTO models (name, title, fax, web, age, "right", counter) VALUES `
-	rawInsertValuesSQL = `($1, $2, $3, $4, $5, $6, $7)`
-	rawInsertSQL       = rawInsertBaseSQL + rawInsertValuesSQL
-	rawUpdateSQL       = `UPDATE models SET name = $1, title = $2, fax = $3, web = $4, age = $5, "right" = $6, counter = $7 WHERE id = $8`
-	rawSelectSQL       = `SELECT id, name, title, fax, web, age, "right", counter FROM models WHERE id = $1`
-	rawSelectMultiSQL  = `SELECT id, name, title, fax, web, age, "right", counter FROM models WHERE id > 0 LIMIT 100`
+var (
+	rawInsertBaseSQL   string
+	rawInsertValuesSQL string
+	rawInsertSQL       string
+	rawUpdateSQL       string
+	rawSelectSQL       string
+	rawSelectMultiSQL  string
 )
 
+// rawPrepareSQL builds the statements of the raw suite for the selected dialect.
+func rawPrepareSQL() {
+	right := dialect.Quote("right")
+	rawInsertBaseSQL = `INSERT INTO models (name, title, fax, web, age, ` + right + `, counter) VALUES `
+	rawInsertValuesSQL = `(?, ?, ?, ?, ?, ?, ?)`
+	rawInsertSQL = dialect.Rebind(rawInsertBaseSQL + rawInsertValuesSQL)
+	if dialect.Returning {
+		rawInsertSQL += ` RETURNING id`
+	}
+	rawUpdateSQL = dialect.Rebind(`UPDATE models SET name = ?, title = ?, fax = ?, web = ?, age = ?, ` + right + ` = ?, counter = ? WHERE id = ?`)
+	rawSelectSQL = dialect.Rebind(`SELECT id, name, title, fax, web, age, ` + right + `, counter FROM models WHERE id = ?`)
+	rawSelectMultiSQL = dialect.Rebind(`SELECT id, name, title, fax, web, age, ` + right + `, counter FROM models WHERE id > ? ORDER BY id LIMIT ?`)
+}
+
 func init() {
-	st := NewSuite("raw")
-	st.InitF = func() {
-		st.AddBenchmark("Insert", 2000*ORM_MULTI, 0, RawInsert)
-		st.AddBenchmark("BulkInsert 100 row", 2000*ORM_MULTI, 0, RawInsertMulti)
-		st.AddBenchmark("Update", 2000*ORM_MULTI, 0, RawUpdate)
-		st.AddBenchmark("Read", 2000*ORM_MULTI, 0, RawRead)
-		st.AddBenchmark("MultiRead limit 2000", 2000*ORM_MULTI, 2000, RawReadSlice)
+	NewAdapterSuite("raw", new(rawAdapter))
+}
 
-		raw, _ = sql.Open("postgres", ORM_SOURCE)
-	}
+// rawAdapter uses database/sql with statements prepared for every
+// benchmark.
+type rawAdapter struct {
+	db *sql.DB
+
+	insert, update, get, find *sql.Stmt
+	// insertMany caches the bulk insert statement by number of rows.
+	insertMany sync.Map
 }
 
-func RawInsert(b *B) {
-	var m *Model
-	var stmt *sql.Stmt
-	wrapExecute(b, func() {
-		var err error
-		initDB()
-		m = NewModel()
-		stmt, err = raw.Prepare(rawInsertSQL)
-		if err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	})
-	defer stmt.Close()
+func (r *rawAdapter) Connect() error {
+	db, err := sql.Open(dialect.DriverName, ORM_SOURCE)
+	if err != nil {
+		return err
+	}
+	db.SetMaxIdleConns(ORM_MAX_IDLE)
+	db.SetMaxOpenConns(ORM_MAX_CONN)
+	r.db = db
+	rawPrepareSQL()
+	return nil
+}
 
-	for i := 0; i < b.N; i++ {
-		// pq dose not support the LastInsertId method.
-		_, err := stmt.Exec(m.Name, m.Title, m.Fax, m.Web, m.Age, m.Right, m.Counter)
+// Prepare prepares the statements again, the ones of the last benchmark
+// refer to the dropped table.
+func (r *rawAdapter) Prepare(ctx context.Context) error {
+	for _, s := range []struct {
+		stmt  **sql.Stmt
+		query string
+	}{
+		{&r.insert, rawInsertSQL},
+		{&r.update, rawUpdateSQL},
+		{&r.get, rawSelectSQL},
+		{&r.find, rawSelectMultiSQL},
+	} {
+		if *s.stmt != nil {
+			(*s.stmt).Close()
+		}
+		stmt, err := r.db.PrepareContext(ctx, s.query)
 		if err != nil {
-			fmt.Println(err)
-			b.FailNow()
+			return err
 		}
+		*s.stmt = stmt
 	}
+	return nil
 }
 
-func rawInsert(m *Model) error {
-	// pq dose not support the LastInsertId method.
-	_, err := raw.Exec(rawInsertSQL, m.Name, m.Title, m.Fax, m.Web, m.Age, m.Right, m.Counter)
+func (r *rawAdapter) InsertOne(ctx context.Context, m *Model) error {
+	if dialect.Returning {
+		return r.insert.QueryRowContext(ctx, m.Name, m.Title, m.Fax, m.Web, m.Age, m.Right, m.Counter).Scan(&m.Id)
+	}
+	res, err := r.insert.ExecContext(ctx, m.Name, m.Title, m.Fax, m.Web, m.Age, m.Right, m.Counter)
 	if err != nil {
 		return err
 	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	m.Id = int(id)
 	return nil
 }
 
-func RawInsertMulti(b *B) {
-	var ms []*Model
-	wrapExecute(b, func() {
-		initDB()
-
-		ms = make([]*Model, 0, 100)
-		for i := 0; i < 100; i++ {
-			ms = append(ms, NewModel())
-		}
-	})
-
-	var valuesSQL string
-	counter := 1
-	for i := 0; i < 100; i++ {
-		hoge := ""
-		for j := 0; j < 7; j++ {
-			if j != 6 {
-				hoge += "$" + strconv.Itoa(counter) + ","
-			} else {
-				hoge += "$" + strconv.Itoa(counter)
-			}
-			counter++
-
-		}
-		if i != 99 {
-			valuesSQL += "(" + hoge + "),"
-		} else {
-			valuesSQL += "(" + hoge + ")"
-		}
+// insertManySQL returns the bulk insert statement for n rows.
+func (r *rawAdapter) insertManySQL(n int) string {
+	if q, ok := r.insertMany.Load(n); ok {
+		return q.(string)
 	}
-
-	for i := 0; i < b.N; i++ {
-		nFields := 7
-		query := rawInsertBaseSQL + valuesSQL
-		args := make([]interface{}, len(ms)*nFields)
-		for j := range ms {
-			offset := j * nFields
-			args[offset+0] = ms[j].Name
-			args[offset+1] = ms[j].Title
-			args[offset+2] = ms[j].Fax
-			args[offset+3] = ms[j].Web
-			args[offset+4] = ms[j].Age
-			args[offset+5] = ms[j].Right
-			args[offset+6] = ms[j].Counter
-		}
-		// pq dose not support the LastInsertId method.
-		_, err := raw.Exec(query, args...)
-		if err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
+	values := make([]string, n)
+	for i := range values {
+		values[i] = rawInsertValuesSQL
 	}
+	q := dialect.Rebind(rawInsertBaseSQL + strings.Join(values, ","))
+	r.insertMany.Store(n, q)
+	return q
 }
 
-func RawUpdate(b *B) {
-	var m *Model
-	var stmt *sql.Stmt
-	wrapExecute(b, func() {
-		var err error
-		initDB()
-		m = NewModel()
-		rawInsert(m)
-		stmt, err = raw.Prepare(rawUpdateSQL)
-		if err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	})
-	defer stmt.Close()
-
-	for i := 0; i < b.N; i++ {
-		_, err := stmt.Exec(m.Name, m.Title, m.Fax, m.Web, m.Age, m.Right, m.Counter, m.Id)
-		if err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
+func (r *rawAdapter) InsertMany(ctx context.Context, ms []*Model) error {
+	const nFields = 7
+	args := make([]interface{}, len(ms)*nFields)
+	for j, m := range ms {
+		offset := j * nFields
+		args[offset+0] = m.Name
+		args[offset+1] = m.Title
+		args[offset+2] = m.Fax
+		args[offset+3] = m.Web
+		args[offset+4] = m.Age
+		args[offset+5] = m.Right
+		args[offset+6] = m.Counter
 	}
+	_, err := r.db.ExecContext(ctx, r.insertManySQL(len(ms)), args...)
+	return err
 }
 
-func RawRead(b *B) {
-	var m *Model
-	var stmt *sql.Stmt
-	wrapExecute(b, func() {
-		var err error
-		initDB()
-		m = NewModel()
-		rawInsert(m)
-		stmt, err = raw.Prepare(rawSelectSQL)
-		if err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	})
-	defer stmt.Close()
-
-	for i := 0; i < b.N; i++ {
-		var mout Model
-		err := stmt.QueryRow(1).Scan(
-			//err := stmt.QueryRow(m.Id).Scan(
-			&mout.Id,
-			&mout.Name,
-			&mout.Title,
-			&mout.Fax,
-			&mout.Web,
-			&mout.Age,
-			&mout.Right,
-			&mout.Counter,
-		)
-		if err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	}
+func (r *rawAdapter) UpdateByPK(ctx context.Context, m *Model) error {
+	_, err := r.update.ExecContext(ctx, m.Name, m.Title, m.Fax, m.Web, m.Age, m.Right, m.Counter, m.Id)
+	return err
 }
 
-func RawReadSlice(b *B) {
-	var m *Model
-	var stmt *sql.Stmt
-	wrapExecute(b, func() {
-		var err error
-		initDB()
-		m = NewModel()
-		for i := 0; i < b.L; i++ {
-			err = rawInsert(m)
-			if err != nil {
-				fmt.Println(err)
-				b.FailNow()
-			}
-		}
-		stmt, err = raw.Prepare(strings.Replace(rawSelectMultiSQL, "100", strconv.Itoa(b.L), -1))
-		if err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	})
-	defer stmt.Close()
+func (r *rawAdapter) GetByPK(ctx context.Context, id int, m *Model) error {
+	return r.get.QueryRowContext(ctx, id).Scan(
+		&m.Id,
+		&m.Name,
+		&m.Title,
+		&m.Fax,
+		&m.Web,
+		&m.Age,
+		&m.Right,
+		&m.Counter,
+	)
+}
 
-	for i := 0; i < b.N; i++ {
-		var j int
-		models := make([]Model, b.L)
-		rows, err := stmt.Query()
+func (r *rawAdapter) FindRange(ctx context.Context, minID, limit int) (int, error) {
+	var j int
+	models := make([]Model, limit)
+	rows, err := r.find.QueryContext(ctx, minID, limit)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	for j = 0; rows.Next() && j < len(models); j++ {
+		err = rows.Scan(
+			&models[j].Id,
+			&models[j].Name,
+			&models[j].Title,
+			&models[j].Fax,
+			&models[j].Web,
+			&models[j].Age,
+			&models[j].Right,
+			&models[j].Counter,
+		)
 		if err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-		for j = 0; rows.Next() && j < len(models); j++ {
-			err = rows.Scan(
-				&models[j].Id,
-				&models[j].Name,
-				&models[j].Title,
-				&models[j].Fax,
-				&models[j].Web,
-				&models[j].Age,
-				&models[j].Right,
-				&models[j].Counter,
-			)
-			if err != nil {
-				fmt.Println(err)
-				b.FailNow()
-			}
-		}
-		models = models[:j]
-		if err = rows.Err(); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-		if err = rows.Close(); err != nil {
-			fmt.Println(err)
-			b.FailNow()
+			return j, err
 		}
 	}
+	if err = rows.Err(); err != nil {
+		return j, err
+	}
+	return j, rows.Close()
 }
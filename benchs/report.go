@@ -0,0 +1,102 @@
+package benchs
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ReportFormats lists the formats RenderReport understands.
+var ReportFormats = []string{"text", "markdown", "csv", "json"}
+
+// RenderReport renders the results of the suites in format.
+func RenderReport(format string) (string, error) {
+	switch format {
+	case "text":
+		return MakeReport(), nil
+	case "markdown":
+		return MakeMarkdownReport(), nil
+	case "csv":
+		return MakeCSVReport()
+	case "json":
+		data, err := json.MarshalIndent(CollectResults(), "", "  ")
+		return string(data) + "\n", err
+	}
+	return "", fmt.Errorf("unknown report format %s, expected one of %s", format, strings.Join(ReportFormats, ", "))
+}
+
+var reportColumns = []string{"operation", "orm", "status", "n", "ns/op", "stddev", "B/op", "allocs/op", "p50", "p99", "runs", "remeasured", "unstable"}
+
+// reportRow returns the reportColumns of b, ns/op, B/op and allocs/op
+// are the means of all repetitions.
+func reportRow(b *B) []string {
+	r := b.result
+	if r.Status != StatusOK {
+		return []string{b.Name, b.Brand, r.Status.String(), "", "", "", "", "", "", "", "0", "", ""}
+	}
+	stats := b.Stats()
+	lat := stats.Latency()
+	if lat.Max <= 0 {
+		lat = *r
+	}
+	return []string{
+		b.Name,
+		b.Brand,
+		r.Status.String(),
+		strconv.Itoa(b.N),
+		fmt.Sprintf("%.0f", stats.Mean()),
+		fmt.Sprintf("%.0f", stats.Stddev()),
+		fmt.Sprintf("%.0f", mean(stats.BytesPerOp())),
+		fmt.Sprintf("%.0f", mean(stats.AllocsPerOp())),
+		strconv.FormatInt(lat.P50.Nanoseconds(), 10),
+		strconv.FormatInt(lat.P99.Nanoseconds(), 10),
+		strconv.Itoa(len(b.samples)),
+		strconv.Itoa(len(b.outliers)),
+		strconv.FormatBool(b.unstable),
+	}
+}
+
+// MakeMarkdownReport renders one table per operation.
+func MakeMarkdownReport() (result string) {
+	if environment != nil {
+		result += "```\n" + environment.String() + "```\n\n"
+	}
+	for _, benchs := range reportGroups() {
+		if len(benchs) == 0 {
+			continue
+		}
+		result += "### " + benchs[0].Name + "\n\n"
+		result += "| " + strings.Join(reportColumns[1:], " | ") + " |\n"
+		result += strings.Repeat("|---", len(reportColumns)-1) + "|\n"
+		for _, b := range benchs {
+			row := reportRow(b)
+			if b.result.Status != StatusOK {
+				msg := b.result.FailedMsg
+				if b.result.Status == StatusSkipped {
+					msg = b.result.SkippedMsg
+				}
+				row[2] += ": " + strings.Replace(msg, "|", `\|`, -1)
+			}
+			result += "| " + strings.Join(row[1:], " | ") + " |\n"
+		}
+		result += "\n"
+	}
+	return
+}
+
+// MakeCSVReport renders one row per ORM and operation.
+func MakeCSVReport() (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write(reportColumns)
+	for _, benchs := range reportGroups() {
+		for _, b := range benchs {
+			w.Write(reportRow(b))
+		}
+	}
+	w.Flush()
+	return buf.String(), w.Error()
+}
@@ -0,0 +1,168 @@
+package benchs
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"time"
+)
+
+// Results is what the run command saves, report and compare load it.
+type Results struct {
+	Dialect string
+	Date    time.Time
+	Config  *RunConfig   `json:",omitempty"`
+	Env     *Environment `json:",omitempty"`
+	Suites  []SuiteResult
+}
+
+// SuiteResult holds the benchmarks of one ORM in the order they were
+// registered, which is the order MakeReport lines them up in.
+type SuiteResult struct {
+	Brand      string
+	SkippedMsg string `json:",omitempty"`
+	Benchmarks []SavedBenchmark
+}
+
+// SavedBenchmark is one benchmark with the result of its last run and the
+// successful results of every -count repetition.
+type SavedBenchmark struct {
+	Name    string
+	N       int
+	L       int
+	Result  *BenchmarkResult
+	Samples []*BenchmarkResult `json:",omitempty"`
+	// Outliers are the samples dropped and measured again, Unstable is
+	// set when the samples still had outliers.
+	Outliers []*BenchmarkResult `json:",omitempty"`
+	Unstable bool               `json:",omitempty"`
+}
+
+// Stats summarizes the saved samples like B.Stats.
+func (sb SavedBenchmark) Stats() Stats {
+	return Stats{Samples: sb.Samples}
+}
+
+// CollectResults gathers the results of every suite that ran.
+func CollectResults() *Results {
+	r := &Results{Date: time.Now(), Env: environment}
+	if dialect != nil {
+		r.Dialect = dialect.Name
+	}
+	for _, name := range BrandNames {
+		s, ok := benchmarks[name]
+		if !ok {
+			continue
+		}
+		sr := SuiteResult{Brand: name, SkippedMsg: s.skipMsg}
+		for _, b := range s.benchs {
+			if b.result == nil {
+				continue
+			}
+			sr.Benchmarks = append(sr.Benchmarks, SavedBenchmark{
+				Name:     b.Name,
+				N:        b.N,
+				L:        b.L,
+				Result:   b.result,
+				Samples:  b.samples,
+				Outliers: b.outliers,
+				Unstable: b.unstable,
+			})
+		}
+		if len(sr.Benchmarks) > 0 || len(sr.SkippedMsg) > 0 {
+			r.Suites = append(r.Suites, sr)
+		}
+	}
+	return r
+}
+
+// Restore loads r into the suites, so MakeReport renders it as if it
+// had just run. Registered benchmarks get their results replaced, others
+// are added and suites that aren't compiled in are registered by name.
+func (r *Results) Restore() {
+	if d, ok := dialects[r.Dialect]; ok {
+		dialect = d
+	}
+	// the results of an -isolate child come without one
+	if r.Env != nil {
+		environment = r.Env
+	}
+	r.apply(true)
+}
+
+// Resume loads a checkpoint into the benchmarks registered by
+// PrepareBenchmarks, so the run skips what already finished. It returns
+// the number of successful runs loaded.
+func (r *Results) Resume() int {
+	return r.apply(false)
+}
+
+func (r *Results) apply(add bool) (runs int) {
+	for _, sr := range r.Suites {
+		s, ok := benchmarks[sr.Brand]
+		if !ok {
+			if !add {
+				continue
+			}
+			s = NewSuite(sr.Brand)
+		}
+		if add {
+			s.prepared = true
+			s.skipMsg = sr.SkippedMsg
+		}
+		for _, sb := range sr.Benchmarks {
+			b := s.find(sb.Name)
+			if b == nil {
+				if !add {
+					continue
+				}
+				b = &B{Brand: sr.Brand, Name: sb.Name, L: sb.L}
+				s.benchs = append(s.benchs, b)
+			}
+			b.N = sb.N
+			b.result = sb.Result
+			b.samples = sb.Samples
+			b.outliers = sb.Outliers
+			b.unstable = sb.Unstable
+			runs += len(sb.Samples)
+		}
+		if len(s.benchs) > benchmarksNums {
+			benchmarksNums = len(s.benchs)
+		}
+	}
+	return
+}
+
+// Find returns the saved benchmark of brand named name.
+func (r *Results) Find(brand, name string) (SavedBenchmark, bool) {
+	for _, sr := range r.Suites {
+		if sr.Brand != brand {
+			continue
+		}
+		for _, sb := range sr.Benchmarks {
+			if sb.Name == name {
+				return sb, true
+			}
+		}
+	}
+	return SavedBenchmark{}, false
+}
+
+func WriteResults(path string, r *Results) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func ReadResults(path string) (*Results, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r := new(Results)
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
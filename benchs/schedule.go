@@ -0,0 +1,57 @@
+package benchs
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// RunInterleaved runs the named suites in randomized blocks instead of one
+// suite after the other, so a slow phase of the machine or the database
+// hits every ORM alike. All suites are connected first. Each repetition
+// visits the operations in random order, and each operation runs for all
+// ORMs back to back in random order. A benchmark that didn't succeed
+// isn't repeated, one resumed from a checkpoint runs what it has left.
+// Outliers dropped in the last repetition are measured again in more
+// rounds.
+func RunInterleaved(names []string, rd *rand.Rand) {
+	var suites []*Suite
+	for _, name := range names {
+		if s := startSuite(name); s != nil {
+			suites = append(suites, s)
+		}
+	}
+
+	count := ORM_COUNT
+	if count < 1 {
+		count = 1
+	}
+	for rep := 0; rep < count || pending(suites, count); rep++ {
+		for _, op := range rd.Perm(benchmarksNums) {
+			for _, i := range rd.Perm(len(suites)) {
+				b := suites[i].benchs[op]
+				if b.remaining(count) <= 0 {
+					continue
+				}
+				if Interrupted() {
+					return
+				}
+				b.run()
+				fmt.Printf("%10s %25s: %6d ", b.Brand, b.Name, b.N)
+				fmt.Println(b.result.String())
+				checkpoint()
+			}
+		}
+	}
+}
+
+// pending reports whether a benchmark of suites has repetitions left.
+func pending(suites []*Suite, count int) bool {
+	for _, s := range suites {
+		for _, b := range s.benchs {
+			if b.remaining(count) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
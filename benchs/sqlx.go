@@ -1,98 +1,77 @@
 package benchs
 
 import (
-	"fmt"
+	"context"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"
 )
 
-var sqlxdb *sqlx.DB
+var (
+	sqlxInsertSQL      string
+	sqlxUpdateSQL      string
+	sqlxSelectSQL      string
+	sqlxSelectMultiSQL string
+)
 
 func init() {
-	st := NewSuite("sqlx")
-	st.InitF = func() {
-		st.AddBenchmark("Insert", 2000*ORM_MULTI, 0, SqlxInsert)
-		st.AddBenchmark("BulkInsert 100 row", 2000*ORM_MULTI, 0, SqlxInsertMulti)
-		st.AddBenchmark("Update", 2000*ORM_MULTI, 0, SqlxUpdate)
-		st.AddBenchmark("Read", 2000*ORM_MULTI, 0, SqlxRead)
-		st.AddBenchmark("MultiRead limit 2000", 2000*ORM_MULTI, 2000, SqlxReadSlice)
+	NewAdapterSuite("sqlx", new(sqlxAdapter))
+}
 
-		db, err := sqlx.Connect("postgres", ORM_SOURCE)
-		checkErr(err)
-		sqlxdb = db
-	}
+type sqlxAdapter struct {
+	db *sqlx.DB
 }
 
-func SqlxInsert(b *B) {
-	var m *Model
-	wrapExecute(b, func() {
-		initDB()
-		m = NewModel()
-	})
-	var err error
-	for i := 0; i < b.N; i++ {
-		if err = sqlxdb.QueryRowx(`INSERT INTO models (name, title, fax, web, age, "right", counter) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING *`,
-			m.Name, m.Title, m.Fax, m.Web, m.Age, m.Right, m.Counter).StructScan(m); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
+func (s *sqlxAdapter) Connect() error {
+	db, err := sqlx.Connect(dialect.DriverName, ORM_SOURCE)
+	if err != nil {
+		return err
 	}
-}
+	db.SetMaxIdleConns(ORM_MAX_IDLE)
+	db.SetMaxOpenConns(ORM_MAX_CONN)
+	s.db = db
 
-func SqlxInsertMulti(b *B) {
-	panic(fmt.Errorf("benchmark not implemeted yet - https://github.com/jmoiron/sqlx/issues/134"))
+	right := dialect.Quote("right")
+	sqlxInsertSQL = db.Rebind(`INSERT INTO models (name, title, fax, web, age, ` + right + `, counter) VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	sqlxUpdateSQL = db.Rebind(`UPDATE models SET name = ?, title = ?, fax = ?, web = ?, age = ?, ` + right + ` = ?, counter = ? WHERE id = ?`)
+	sqlxSelectSQL = db.Rebind(`SELECT * FROM models WHERE id = ?`)
+	sqlxSelectMultiSQL = db.Rebind(`SELECT * FROM models WHERE id > ? ORDER BY id LIMIT ?`)
+	return nil
 }
 
-func SqlxUpdate(b *B) {
-	var m *Model
-	wrapExecute(b, func() {
-		initDB()
-		m = NewModel()
-		if err := sqlxdb.QueryRowx(`INSERT INTO models (name, title, fax, web, age, "right", counter) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING *`,
-			m.Name, m.Title, m.Fax, m.Web, m.Age, m.Right, m.Counter).StructScan(m); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	})
-
-	for i := 0; i < b.N; i++ {
-		sqlxdb.MustExec(`UPDATE models SET name = $1, title = $2, fax = $3, web = $4, age = $5, "right" = $6, counter = $7 WHERE id = $8`,
-			m.Name, m.Title, m.Fax, m.Web, m.Age, m.Right, m.Counter, m.Id)
+// InsertOne stores the generated id back into m.
+func (s *sqlxAdapter) InsertOne(ctx context.Context, m *Model) error {
+	if dialect.Returning {
+		return s.db.QueryRowxContext(ctx, sqlxInsertSQL+` RETURNING id`,
+			m.Name, m.Title, m.Fax, m.Web, m.Age, m.Right, m.Counter).Scan(&m.Id)
+	}
+	res, err := s.db.ExecContext(ctx, sqlxInsertSQL, m.Name, m.Title, m.Fax, m.Web, m.Age, m.Right, m.Counter)
+	if err != nil {
+		return err
 	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	m.Id = int(id)
+	return nil
 }
 
-func SqlxRead(b *B) {
-	var m *Model
-	wrapExecute(b, func() {
-		initDB()
-		m = NewModel()
-		sqlxdb.MustExec(`INSERT INTO models (name, title, fax, web, age, "right", counter) VALUES ($1, $2, $3, $4, $5, $6, $7)`, m.Name, m.Title, m.Fax, m.Web, m.Age, m.Right, m.Counter)
-	})
-	for i := 0; i < b.N; i++ {
-		m := []Model{}
-		if err := sqlxdb.Select(&m, "SELECT * FROM models"); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	}
+func (s *sqlxAdapter) InsertMany(ctx context.Context, ms []*Model) error {
+	return NotSupported("benchmark not implemeted yet - https://github.com/jmoiron/sqlx/issues/134")
+}
+
+func (s *sqlxAdapter) UpdateByPK(ctx context.Context, m *Model) error {
+	_, err := s.db.ExecContext(ctx, sqlxUpdateSQL,
+		m.Name, m.Title, m.Fax, m.Web, m.Age, m.Right, m.Counter, m.Id)
+	return err
 }
 
-func SqlxReadSlice(b *B) {
-	var m *Model
-	wrapExecute(b, func() {
-		initDB()
-		m = NewModel()
-		for i := 0; i < b.L; i++ {
-			sqlxdb.MustExec(`INSERT INTO models (name, title, fax, web, age, "right", counter) VALUES ($1, $2, $3, $4, $5, $6, $7)`, m.Name, m.Title, m.Fax, m.Web, m.Age, m.Right, m.Counter)
-		}
-	})
+func (s *sqlxAdapter) GetByPK(ctx context.Context, id int, m *Model) error {
+	return s.db.GetContext(ctx, m, sqlxSelectSQL, id)
+}
 
-	for i := 0; i < b.N; i++ {
-		var models []*Model
-		if err := sqlxdb.Select(&models, "SELECT * FROM models WHERE id > $1 LIMIT $2", 0, b.L); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	}
+func (s *sqlxAdapter) FindRange(ctx context.Context, minID, limit int) (int, error) {
+	var models []*Model
+	err := s.db.SelectContext(ctx, &models, sqlxSelectMultiSQL, minID, limit)
+	return len(models), err
 }
@@ -0,0 +1,148 @@
+package benchs
+
+import (
+	"fmt"
+	"math"
+)
+
+// Stats summarizes the successful samples of a benchmark run -count times.
+type Stats struct {
+	Samples []*BenchmarkResult
+}
+
+// tTable holds the two sided 95% critical values of Student's t
+// distribution for 1 to 30 degrees of freedom.
+var tTable = [...]float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+func tCritical95(df int) float64 {
+	if df < 1 {
+		return math.Inf(1)
+	}
+	if df <= len(tTable) {
+		return tTable[df-1]
+	}
+	return 1.960
+}
+
+func (s Stats) values(f func(r *BenchmarkResult) float64) []float64 {
+	vs := make([]float64, len(s.Samples))
+	for i, r := range s.Samples {
+		vs[i] = f(r)
+	}
+	return vs
+}
+
+// NsPerOp returns ns/op of every sample.
+func (s Stats) NsPerOp() []float64 {
+	return s.values(func(r *BenchmarkResult) float64 { return r.nsPerOp() })
+}
+
+// BytesPerOp returns B/op of every sample.
+func (s Stats) BytesPerOp() []float64 {
+	return s.values(func(r *BenchmarkResult) float64 { return float64(r.AllocedBytesPerOp()) })
+}
+
+// AllocsPerOp returns allocs/op of every sample.
+func (s Stats) AllocsPerOp() []float64 {
+	return s.values(func(r *BenchmarkResult) float64 { return float64(r.AllocsPerOp()) })
+}
+
+func mean(vs []float64) float64 {
+	if len(vs) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range vs {
+		sum += v
+	}
+	return sum / float64(len(vs))
+}
+
+// stddev is the sample standard deviation.
+func stddev(vs []float64) float64 {
+	if len(vs) < 2 {
+		return 0
+	}
+	m := mean(vs)
+	var sum float64
+	for _, v := range vs {
+		sum += (v - m) * (v - m)
+	}
+	return math.Sqrt(sum / float64(len(vs)-1))
+}
+
+func (s Stats) Mean() float64 {
+	return mean(s.NsPerOp())
+}
+
+func (s Stats) Stddev() float64 {
+	return stddev(s.NsPerOp())
+}
+
+// CI95 returns the 95% confidence interval of the mean ns/op.
+func (s Stats) CI95() (lo, hi float64) {
+	vs := s.NsPerOp()
+	m := mean(vs)
+	if len(vs) < 2 {
+		return m, m
+	}
+	half := tCritical95(len(vs)-1) * stddev(vs) / math.Sqrt(float64(len(vs)))
+	return m - half, m + half
+}
+
+// Overlaps reports whether the confidence intervals of s and o overlap,
+// i.e. the difference between both is within the noise.
+func (s Stats) Overlaps(o Stats) bool {
+	if len(s.Samples) < 2 || len(o.Samples) < 2 {
+		return false
+	}
+	lo1, hi1 := s.CI95()
+	lo2, hi2 := o.CI95()
+	return lo1 <= hi2 && lo2 <= hi1
+}
+
+// Busy returns how many samples ran on a busy machine.
+func (s Stats) Busy() (n int) {
+	for _, r := range s.Samples {
+		if r.Load.Busy() {
+			n++
+		}
+	}
+	return
+}
+
+// Latency merges the latency histograms of all samples.
+func (s Stats) Latency() (r BenchmarkResult) {
+	var h *Histogram
+	for _, sample := range s.Samples {
+		if sample.Histogram == nil {
+			continue
+		}
+		if h == nil {
+			h = NewHistogram()
+		}
+		h.Merge(sample.Histogram)
+	}
+	if h != nil {
+		r.setLatency(h)
+	}
+	return
+}
+
+func (s Stats) String() string {
+	m := s.Mean()
+	sd := s.Stddev()
+	lo, hi := s.CI95()
+	var pct float64
+	if m > 0 {
+		pct = sd / m * 100
+	}
+	allocs := mean(s.AllocsPerOp())
+	bytes := mean(s.BytesPerOp())
+	return fmt.Sprintf("   %10.0f ± %-8.0f ns/op (%4.1f%%)  95%% CI [%.0f, %.0f]%8.0f B/op  %5.0f allocs/op  (%d runs)",
+		m, sd, pct, lo, hi, bytes, allocs, len(s.Samples))
+}
@@ -1,14 +1,17 @@
 package benchs
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
+	"regexp"
+	"time"
 )
 
 type Model struct {
 	Id      int    `column:"id" qbs:"pk" orm:"auto" gorm:"primary_key" db:"id" xorm:"autoincr"`
-	Name    string `column:"name" db:"name""`
+	Name    string `column:"name" db:"name"`
 	Title   string `column:"title" db:"title"`
 	Fax     string `column:"fax" db:"fax"`
 	Web     string `column:"web" db:"web"`
@@ -31,8 +34,13 @@ func (entity *Model) GetPKColumnName() string {
 	return "id"
 }
 
+// GetPkSequence is the value zorm inserts as id, postgres takes it from
+// the serial sequence, empty lets mysql auto increment it.
 func (entity *Model) GetPkSequence() string {
-	return "id"
+	if dialect.Name == "postgres" {
+		return "nextval('models_id_seq')"
+	}
+	return ""
 }
 
 // NewModel initializes a new model struct for inserts
@@ -49,11 +57,50 @@ func NewModel() *Model {
 	return m
 }
 
+// NewModels initializes n model structs for bulk inserts
+func NewModels(n int) []*Model {
+	ms := make([]*Model, 0, n)
+	for i := 0; i < n; i++ {
+		ms = append(ms, NewModel())
+	}
+	return ms
+}
+
+// copy returns a copy of m, so RunParallel workers don't share one model.
+func (m *Model) copy() *Model {
+	c := *m
+	return &c
+}
+
 var (
-	ORM_MULTI    int
-	ORM_MAX_IDLE int
-	ORM_MAX_CONN int
-	ORM_SOURCE   string
+	ORM_MULTI       int
+	ORM_MAX_IDLE    int
+	ORM_MAX_CONN    int
+	ORM_SOURCE      string
+	ORM_CONCURRENCY int
+	ORM_BENCHTIME   BenchTime
+	ORM_COUNT       int
+	ORM_WARMUP      BenchTime
+	// ORM_TIMEOUT limits every benchmark, ORM_DEADLINE the whole run.
+	ORM_TIMEOUT  time.Duration
+	ORM_DEADLINE time.Time
+	// ORM_BENCH selects the benchmarks to run by name, nil runs all.
+	ORM_BENCH *regexp.Regexp
+	// ORM_OVERRIDES replaces N and L of benchmarks by name or orm/name.
+	ORM_OVERRIDES map[string]BenchmarkOverride
+	// ORM_PINNED is the N each sample of a benchmark runs by orm/name,
+	// set by -replay, it wins over ORM_BENCHTIME.
+	ORM_PINNED map[string][]int
+	// ORM_CALIBRATE subtracts the harness overhead from the results.
+	ORM_CALIBRATE bool
+	// ORM_OUTLIER is the modified z-score beyond which a -count sample is
+	// an outlier, 0 disables. ORM_REMEASURE limits how many outliers of a
+	// benchmark are measured again.
+	ORM_OUTLIER   float64
+	ORM_REMEASURE int
+	// ORM_CHECKPOINT is the file the results are saved to after every
+	// benchmark run, empty disables.
+	ORM_CHECKPOINT string
 )
 
 // checkErr prints and exists on error
@@ -68,35 +115,38 @@ func checkErr(err error) {
 func wrapExecute(b *B, cbk func()) {
 	b.StopTimer()
 	defer b.StartTimer()
-	cbk()
+	b.excludeMem(cbk)
 }
 
-// initDB recreates tables before executing any benchmark.
-func initDB() {
-	sqls := []string{
-		`DROP TABLE IF EXISTS models;`,
-		`CREATE TABLE models (
-			id SERIAL NOT NULL,
-			name text NOT NULL,
-			title text NOT NULL,
-			fax text NOT NULL,
-			web text NOT NULL,
-			age integer NOT NULL,
-			"right" boolean NOT NULL,
-			counter bigint NOT NULL,
-			CONSTRAINT models_pkey PRIMARY KEY (id)
-			) WITH (OIDS=FALSE);`,
+// lastID returns the highest id of the models table, for adapters whose
+// InsertOne doesn't set it.
+func lastID(ctx context.Context) (int, error) {
+	DB, err := sql.Open(dialect.DriverName, ORM_SOURCE)
+	if err != nil {
+		return 0, err
 	}
-
-	DB, err := sql.Open("postgres", ORM_SOURCE)
-	checkErr(err)
 	defer DB.Close()
 
-	err = DB.Ping()
-	checkErr(err)
+	var id int
+	err = DB.QueryRowContext(ctx, "SELECT max(id) FROM models").Scan(&id)
+	return id, err
+}
+
+// initDB recreates tables before executing any benchmark.
+func initDB(ctx context.Context) error {
+	DB, err := sql.Open(dialect.DriverName, ORM_SOURCE)
+	if err != nil {
+		return err
+	}
+	defer DB.Close()
 
-	for _, stmt := range sqls {
-		_, err = DB.Exec(stmt)
-		checkErr(err)
+	if err := DB.PingContext(ctx); err != nil {
+		return err
+	}
+	for _, stmt := range dialect.Schema {
+		if _, err := DB.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
 	}
+	return nil
 }
@@ -0,0 +1,122 @@
+package benchs
+
+// bulkRows is how many rows BulkInsert inserts with one statement.
+const bulkRows = 100
+
+// Workload is an operation run alike against every adapter, each adapter
+// suite registers it as a benchmark running N*ORM_MULTI times with limit L.
+type Workload struct {
+	Name string
+	N, L int
+	Run  func(b *B, a Adapter)
+}
+
+// workloads are registered in this order, MakeReport lines them up by
+// position.
+var workloads = []Workload{
+	{"Insert", 2000, 0, insertWorkload},
+	{"BulkInsert 100 row", 2000, 0, bulkInsertWorkload},
+	{"Update", 2000, 0, updateWorkload},
+	{"Read", 2000, 0, readWorkload},
+	{"MultiRead limit 1000", 2000, 1000, findRangeWorkload},
+}
+
+// AddWorkload appends w to the workloads of all adapter suites, call it
+// from an init function. A suite registering its benchmarks itself needs
+// one in the same position, MakeReport lines them up by position.
+func AddWorkload(w Workload) {
+	workloads = append(workloads, w)
+}
+
+// setup recreates the models table and inserts rows models, it returns
+// the last one inserted or a new one for rows 0.
+func setup(b *B, a Adapter, rows int) (m *Model) {
+	wrapExecute(b, func() {
+		check(b, initDB(b.Context()))
+		if p, ok := a.(Preparer); ok {
+			check(b, p.Prepare(b.Context()))
+		}
+		m = NewModel()
+		for i := 0; i < rows; i++ {
+			m.Id = 0
+			check(b, a.InsertOne(b.Context(), m))
+		}
+		if rows > 0 && m.Id == 0 {
+			id, err := lastID(b.Context())
+			check(b, err)
+			m.Id = id
+		}
+	})
+	return
+}
+
+func insertWorkload(b *B, a Adapter) {
+	m := setup(b, a, 0)
+
+	b.RunParallel(func(pb *PB) {
+		ctx := b.Context()
+		m := m.copy()
+		for pb.Next() {
+			m.Id = 0
+			check(b, a.InsertOne(ctx, m))
+		}
+	})
+}
+
+func bulkInsertWorkload(b *B, a Adapter) {
+	setup(b, a, 0)
+
+	b.RunParallel(func(pb *PB) {
+		ctx := b.Context()
+		ms := NewModels(bulkRows)
+		for pb.Next() {
+			for _, m := range ms {
+				m.Id = 0
+			}
+			check(b, a.InsertMany(ctx, ms))
+		}
+	})
+}
+
+func updateWorkload(b *B, a Adapter) {
+	m := setup(b, a, 1)
+
+	b.RunParallel(func(pb *PB) {
+		ctx := b.Context()
+		m := m.copy()
+		for pb.Next() {
+			check(b, a.UpdateByPK(ctx, m))
+		}
+	})
+}
+
+func readWorkload(b *B, a Adapter) {
+	m := setup(b, a, 1)
+
+	b.RunParallel(func(pb *PB) {
+		ctx := b.Context()
+		var out Model
+		for pb.Next() {
+			out = Model{}
+			check(b, a.GetByPK(ctx, m.Id, &out))
+			if out.Id != m.Id {
+				b.Fatalf("read id %d, got %d", m.Id, out.Id)
+			}
+		}
+	})
+}
+
+func findRangeWorkload(b *B, a Adapter) {
+	setup(b, a, b.L)
+
+	b.RunParallel(func(pb *PB) {
+		ctx := b.Context()
+		for pb.Next() {
+			n, err := a.FindRange(ctx, 0, b.L)
+			check(b, err)
+			if n != b.L {
+				b.Fatalf("read %d of %d rows", n, b.L)
+			}
+		}
+	})
+}
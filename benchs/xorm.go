@@ -1,123 +1,53 @@
 package benchs
 
 import (
-	"fmt"
+	"context"
 
 	"xorm.io/xorm"
 )
 
-var xo *xorm.Session
-
 func init() {
-	st := NewSuite("xorm")
-	st.InitF = func() {
-		st.AddBenchmark("Insert", 2000*ORM_MULTI, 0, XormInsert)
-		st.AddBenchmark("BulkInsert 100 row", 2000*ORM_MULTI, 0, XormInsertMulti)
-		st.AddBenchmark("Update", 2000*ORM_MULTI, 0, XormUpdate)
-		st.AddBenchmark("Read", 2000*ORM_MULTI, 0, XormRead)
-		st.AddBenchmark("MultiRead limit 2000", 2000*ORM_MULTI, 2000, XormReadSlice)
-
-		engine, _ := xorm.NewEngine("postgres", ORM_SOURCE)
-
-		engine.SetMaxIdleConns(ORM_MAX_IDLE)
-		engine.SetMaxOpenConns(ORM_MAX_CONN)
-
-		xo = engine.NewSession()
-
-	}
+	NewAdapterSuite("xorm", new(xormAdapter))
 }
 
-func XormInsert(b *B) {
-	var m *Model
-	wrapExecute(b, func() {
-		initDB()
-		m = NewModel()
-	})
-
-	for i := 0; i < b.N; i++ {
-		m.Id = 0
-		if _, err := xo.Insert(m); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	}
+type xormAdapter struct {
+	engine *xorm.Engine
 }
 
-func XormInsertMulti(b *B) {
-	var ms []*Model
-	wrapExecute(b, func() {
-		initDB()
-		ms = make([]*Model, 0, 100)
-		for i := 0; i < 100; i++ {
-			ms = append(ms, NewModel())
-		}
-	})
-	for i := 0; i < b.N; i++ {
-		if _, err := xo.InsertMulti(&ms); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
+func (x *xormAdapter) Connect() error {
+	engine, err := xorm.NewEngine(dialect.DriverName, ORM_SOURCE)
+	if err != nil {
+		return err
 	}
+	engine.SetMaxIdleConns(ORM_MAX_IDLE)
+	engine.SetMaxOpenConns(ORM_MAX_CONN)
+	x.engine = engine
+	return nil
 }
 
-func XormUpdate(b *B) {
-	var m *Model
-	wrapExecute(b, func() {
-		initDB()
-		m = NewModel()
-		if _, err := xo.Insert(m); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	})
-
-	for i := 0; i < b.N; i++ {
-		if _, err := xo.Update(m); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	}
+func (x *xormAdapter) InsertOne(ctx context.Context, m *Model) error {
+	_, err := x.engine.Context(ctx).Insert(m)
+	return err
 }
 
-func XormRead(b *B) {
-	var m *Model
-	wrapExecute(b, func() {
-		initDB()
-		m = NewModel()
-		if _, err := xo.Insert(m); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	})
-
-	for i := 0; i < b.N; i++ {
-		if _, err := xo.NoCache().Get(m); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	}
+func (x *xormAdapter) InsertMany(ctx context.Context, ms []*Model) error {
+	_, err := x.engine.Context(ctx).Insert(&ms)
+	return err
 }
 
-func XormReadSlice(b *B) {
-	var m *Model
-	wrapExecute(b, func() {
-		initDB()
-		m = NewModel()
-		for i := 0; i < b.L; i++ {
-			m.Id = 0
-			if _, err := xo.Insert(m); err != nil {
-				fmt.Println(err)
-				b.FailNow()
-			}
-		}
-	})
+// UpdateByPK needs ID, without a condition xorm updates every row.
+func (x *xormAdapter) UpdateByPK(ctx context.Context, m *Model) error {
+	_, err := x.engine.Context(ctx).ID(m.Id).Update(m)
+	return err
+}
 
-	for i := 0; i < b.N; i++ {
-		var models []*Model
-		if err := xo.Where("id > ?", 0).NoCache().Limit(b.L).Find(&models); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	}
+func (x *xormAdapter) GetByPK(ctx context.Context, id int, m *Model) error {
+	_, err := x.engine.Context(ctx).NoCache().ID(id).Get(m)
+	return err
+}
 
+func (x *xormAdapter) FindRange(ctx context.Context, minID, limit int) (int, error) {
+	var models []*Model
+	err := x.engine.Context(ctx).Where("id > ?", minID).OrderBy("id").NoCache().Limit(limit).Find(&models)
+	return len(models), err
 }
@@ -2,121 +2,63 @@ package benchs
 
 import (
 	"context"
-	"fmt"
 
 	"gitee.com/chunanyong/zorm"
 )
 
 func init() {
-	st := NewSuite("zorm")
-	st.InitF = func() {
-		st.AddBenchmark("Insert", 2000*ORM_MULTI, 0, ZormInsert)
-		st.AddBenchmark("BulkInsert 100 row", 2000*ORM_MULTI, 0, ZormInsertMulti)
-		st.AddBenchmark("Update", 2000*ORM_MULTI, 0, ZormUpdate)
-		st.AddBenchmark("Read", 2000*ORM_MULTI, 0, ZormRead)
-		st.AddBenchmark("MultiRead limit 1000", 2000*ORM_MULTI, 1000, ZormReadSlice)
-		dataSourceConfig := zorm.DataSourceConfig{
-			DSN:        ORM_SOURCE,
-			DriverName: "postgres",
-			DBType:     "postgresql",
-		}
-		zorm.NewBaseDao(&dataSourceConfig)
+	NewAdapterSuite("zorm", new(zormAdapter))
+}
+
+// zormAdapter uses the default zorm dao, writes run in a transaction as
+// zorm requires.
+type zormAdapter struct{}
+
+func (zormAdapter) Connect() error {
+	dataSourceConfig := zorm.DataSourceConfig{
+		DSN:          ORM_SOURCE,
+		DriverName:   dialect.DriverName,
+		DBType:       dialect.ZormDBType,
+		MaxIdleConns: ORM_MAX_IDLE,
+		MaxOpenConns: ORM_MAX_CONN,
 	}
+	_, err := zorm.NewBaseDao(&dataSourceConfig)
+	return err
 }
 
-func ZormInsert(b *B) {
-	var m *Model
-	wrapExecute(b, func() {
-		initDB()
-		m = NewModel()
+// InsertOne leaves m.Id unset on postgres, zorm only sets an auto
+// increment id.
+func (zormAdapter) InsertOne(ctx context.Context, m *Model) error {
+	_, err := zorm.Transaction(ctx, func(ctx context.Context) (interface{}, error) {
+		return nil, zorm.SaveStruct(ctx, m)
 	})
-	for i := 0; i < b.N; i++ {
-		m.Id = 0
-		_, d := zorm.Transaction(context.Background(), func(ctx context.Context) (interface{}, error) {
-			return nil, zorm.SaveStruct(ctx, m)
-		})
-		if d != nil {
-			fmt.Println(d.Error())
-			b.FailNow()
-		}
-	}
+	return err
 }
 
-func ZormInsertMulti(b *B) {
-	panic(fmt.Errorf("Don't support bulk insert"))
+func (zormAdapter) InsertMany(ctx context.Context, ms []*Model) error {
+	return NotSupported("Don't support bulk insert")
 }
 
-func ZormUpdate(b *B) {
-	var m *Model
-	wrapExecute(b, func() {
-		initDB()
-		m = NewModel()
-		_, d := zorm.Transaction(context.Background(), func(ctx context.Context) (interface{}, error) {
-			return nil, zorm.SaveStruct(ctx, m)
-		})
-		if d != nil {
-			fmt.Println(d.Error())
-			b.FailNow()
-		}
+func (zormAdapter) UpdateByPK(ctx context.Context, m *Model) error {
+	//匿名函数return的error如果不为nil,事务就会回滚
+	_, err := zorm.Transaction(ctx, func(ctx context.Context) (interface{}, error) {
+		return nil, zorm.UpdateStruct(ctx, m)
 	})
-
-	for i := 0; i < b.N; i++ {
-		//匿名函数return的error如果不为nil,事务就会回滚
-		_, d := zorm.Transaction(context.Background(), func(ctx context.Context) (interface{}, error) {
-			return nil, zorm.UpdateStruct(ctx, m)
-		})
-		if d != nil {
-			fmt.Println(d.Error())
-			b.FailNow()
-		}
-	}
+	return err
 }
 
-func ZormRead(b *B) {
-	var m *Model
-	wrapExecute(b, func() {
-		initDB()
-		m = NewModel()
-		_, d := zorm.Transaction(context.Background(), func(ctx context.Context) (interface{}, error) {
-			return nil, zorm.SaveStruct(ctx, m)
-		})
-		if d != nil {
-			fmt.Println(d.Error())
-			b.FailNow()
-		}
-	})
-	for i := 0; i < b.N; i++ {
-		//查询Struct对象列表
-		d := zorm.QueryStruct(context.Background(), zorm.NewSelectFinder(m.TableName()), m)
-		if d != nil {
-			fmt.Println(d.Error())
-			b.FailNow()
-		}
-	}
+func (zormAdapter) GetByPK(ctx context.Context, id int, m *Model) error {
+	return zorm.QueryStruct(ctx, zorm.NewSelectFinder("models").Append(" WHERE id=?", id), m)
 }
 
-func ZormReadSlice(b *B) {
-	var m *Model
-	wrapExecute(b, func() {
-		initDB()
-		m = NewModel()
-		for i := 0; i < b.L; i++ {
-			m.Id = 0
-			_, d := zorm.Transaction(context.Background(), func(ctx context.Context) (interface{}, error) {
-				return nil, zorm.SaveStruct(ctx, m)
-			})
-			if d != nil {
-				fmt.Println(d.Error())
-				b.FailNow()
-			}
-		}
-	})
-	for i := 0; i < b.N; i++ {
-		var models []Model
-		d := zorm.QueryStructList(context.Background(), zorm.NewSelectFinder(m.TableName()).Append(" order by id asc "), &models, zorm.NewPage())
-		if d != nil {
-			fmt.Println(d.Error())
-			b.FailNow()
-		}
-	}
+func (zormAdapter) FindRange(ctx context.Context, minID, limit int) (int, error) {
+	//查询Struct对象列表
+	var models []Model
+	page := zorm.NewPage()
+	page.PageSize = limit
+	finder := zorm.NewSelectFinder("models").Append(" WHERE id>? order by id asc ", minID)
+	// the page only limits the rows, without this zorm counts them all
+	finder.SelectTotalCount = false
+	err := zorm.QueryStructList(ctx, finder, &models, page)
+	return len(models), err
 }
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"goormbenchorm/benchs"
+	"math/rand"
+	"os"
+	"os/exec"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// childFd is the file descriptor a child writes its results to, the first
+// of exec.Cmd.ExtraFiles.
+const childFd = 3
+
+// childGrace is how long a child may overrun -total_timeout before it's
+// killed.
+const childGrace = 10 * time.Second
+
+// childArgs returns the flags given to the parent that a child needs too,
+// whether they came from the command line or the config file.
+func childArgs(fs *flag.FlagSet) (args []string) {
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "orm", "bench", "out", "isolate", "results_fd", "total_timeout", "seed", "checkpoint", "resume":
+			return
+		}
+		args = append(args, "-"+f.Name+"="+f.Value.String())
+	})
+	return
+}
+
+// runChild runs one suite in a child process of this binary and returns
+// the results it sent back. A child that crashes or hangs before sending
+// them fails all of planned.
+func (o *runOptions) runChild(fs *flag.FlagSet, orm, bench string, planned []benchs.SavedBenchmark) *benchs.Results {
+	failed := func(status benchs.Status, msg string) *benchs.Results {
+		sr := benchs.SuiteResult{Brand: orm}
+		for _, sb := range planned {
+			sb.Result = &benchs.BenchmarkResult{Status: status, FailedMsg: msg}
+			sb.Samples = nil
+			sr.Benchmarks = append(sr.Benchmarks, sb)
+		}
+		fmt.Printf("%s: %s\n", orm, msg)
+		return &benchs.Results{Suites: []benchs.SuiteResult{sr}}
+	}
+
+	args := append([]string{"run"}, childArgs(fs)...)
+	args = append(args, "-orm="+orm, "-out=", "-checkpoint=", fmt.Sprintf("-results_fd=%d", childFd), fmt.Sprintf("-seed=%d", o.seed))
+	if bench != "" {
+		args = append(args, "-bench="+bench)
+	}
+	if !benchs.ORM_DEADLINE.IsZero() {
+		left := time.Until(benchs.ORM_DEADLINE)
+		if left <= 0 {
+			return failed(benchs.StatusTimeout, "not run, -total_timeout reached")
+		}
+		args = append(args, "-total_timeout="+left.String())
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return failed(benchs.StatusFailed, "subprocess: "+err.Error())
+	}
+	defer r.Close()
+
+	// the child keeps to the deadline itself, unless it hangs outside of
+	// a benchmark, e.g. while connecting.
+	ctx := context.Background()
+	if !benchs.ORM_DEADLINE.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, benchs.ORM_DEADLINE.Add(childGrace))
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, os.Args[0], args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = []*os.File{w}
+	err = cmd.Start()
+	w.Close()
+	if err != nil {
+		return failed(benchs.StatusFailed, "subprocess: "+err.Error())
+	}
+	setChild(cmd.Process)
+	defer setChild(nil)
+
+	results := new(benchs.Results)
+	decodeErr := json.NewDecoder(r).Decode(results)
+	waitErr := cmd.Wait()
+	if decodeErr != nil {
+		// the child exits non-zero when a benchmark failed, that's only a
+		// crash if it didn't send its results.
+		if waitErr == nil {
+			waitErr = decodeErr
+		}
+		return failed(benchs.StatusFailed, "subprocess crashed: "+waitErr.Error())
+	}
+	return results
+}
+
+// runIsolated runs every suite, or every benchmark with -isolate
+// benchmark, in its own process so the heap and pools one ORM leaves
+// behind don't slow down the ones after it. The results of each child are
+// loaded back into the suites for MakeReport and the checkpoint. A suite
+// or benchmark a resumed checkpoint has all runs of is skipped, one that
+// was partly done runs again.
+func (o *runOptions) runIsolated(fs *flag.FlagSet, orms ListOpts) {
+	if benchs.PrepareBenchmarks(orms) == 0 {
+		fmt.Printf("no benchmark matches -bench %q\n", o.bench)
+		os.Exit(2)
+	}
+	o.resumeCheckpoint()
+
+	orms.Shuffle(rand.New(rand.NewSource(o.seed)))
+	o.startPlan(orms)
+
+	runChild := func(orm, bench string, planned []benchs.SavedBenchmark) {
+		done := true
+		for _, sb := range planned {
+			if sb.Remaining(benchs.ORM_COUNT) > 0 {
+				done = false
+			}
+		}
+		if done || benchs.Interrupted() {
+			return
+		}
+		o.runChild(fs, orm, bench, planned).Restore()
+		if err := benchs.Checkpoint(); err != nil {
+			fmt.Fprintln(os.Stderr, "checkpoint:", err)
+		}
+	}
+
+	for _, orm := range orms {
+		planned := benchs.SuiteBenchmarks(orm)
+		if o.isolate == "benchmark" {
+			// one at a time in suite order, MakeReport lines them up by
+			// position
+			for _, sb := range planned {
+				runChild(orm, "^"+regexp.QuoteMeta(sb.Name)+"$", []benchs.SavedBenchmark{sb})
+			}
+			continue
+		}
+		runChild(orm, o.bench, planned)
+	}
+}
+
+var child struct {
+	sync.Mutex
+	p *os.Process
+}
+
+func setChild(p *os.Process) {
+	child.Lock()
+	child.p = p
+	child.Unlock()
+}
+
+// signalChild passes sig on to the running child. SIGINT from a terminal
+// already reached it as it shares the process group.
+func signalChild(sig os.Signal) {
+	child.Lock()
+	defer child.Unlock()
+	if child.p != nil && sig != os.Interrupt {
+		child.p.Signal(sig)
+	}
+}
+
+// writeChildResults sends the results of a child to its parent.
+func writeChildResults(fd int) error {
+	f := os.NewFile(uintptr(fd), "results")
+	if f == nil {
+		return fmt.Errorf("invalid -results_fd %d", fd)
+	}
+	defer f.Close()
+	return json.NewEncoder(f).Encode(benchs.CollectResults())
+}
@@ -0,0 +1,646 @@
+// Package cli is the command line of the benchmark. A main that blank
+// imports packages registering their own suites calls Main to run them
+// with the built in ones.
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"goormbenchorm/benchs"
+	"math/rand"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+)
+
+type ListOpts []string
+
+func (opts *ListOpts) String() string {
+	return fmt.Sprint(*opts)
+}
+
+func (opts *ListOpts) Set(value string) error {
+	if value == "all" || strings.Index(" "+strings.Join(benchs.BrandNames, " ")+" ", " "+value+" ") != -1 {
+	} else {
+		return fmt.Errorf("wrong run name %s", value)
+	}
+	*opts = append(*opts, value)
+	return nil
+}
+
+// Shuffle shuffles benchmark order
+func (opts ListOpts) Shuffle(rd *rand.Rand) {
+	for i := 0; i < len(opts); i++ {
+		a := rd.Intn(len(opts))
+		b := rd.Intn(len(opts))
+		opts[a], opts[b] = opts[b], opts[a]
+	}
+}
+
+type command struct {
+	name  string
+	args  string
+	short string
+	run   func(c command, args []string) int
+}
+
+var commands = []command{
+	{"run", "[flags]", "run the benchmarks, print the report and save the results", runCmd},
+	{"list", "[flags]", "list the suites and benchmarks with their N and L", listCmd},
+	{"validate", "[flags]", "run every benchmark once to check it works", validateCmd},
+	{"report", "[flags] [results.json]", "render saved results", reportCmd},
+	{"compare", "old.json new.json", "compare two saved results, changes that aren't significant show as ~", compareCmd},
+	{"budget", "budgets.txt [results.json]", "check saved results against a budgets file", budgetCmd},
+	{"rerun", "[flags] results.manifest.json", "run the plan of a manifest again with the same flags, seed and N", rerunCmd},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: %s <command> [arguments]\n\ncommands:\n", os.Args[0])
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-9s %s\n", c.name, c.short)
+	}
+	fmt.Fprintf(os.Stderr, "\nrun is the default command, see %s <command> -h for its flags\n", os.Args[0])
+}
+
+func newFlagSet(c command) *flag.FlagSet {
+	fs := flag.NewFlagSet(c.name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s %s %s\n\n%s\n\n", os.Args[0], c.name, c.args, c.short)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// runOptions are the flags shared by the commands that run suites.
+type runOptions struct {
+	orms         ListOpts
+	dialect      string
+	bench        string
+	totalTimeout time.Duration
+	isolate      string
+	resume       bool
+	schedule     string
+	seed         int64
+	config       string
+	profile      string
+	// manifest is where the plan is written, empty disables. written is
+	// the plan written there, the N run are recorded in it at the end.
+	manifest string
+	written  *benchs.Manifest
+	// replay is the manifest given to -replay, plan what it holds.
+	replay string
+	plan   *benchs.Manifest
+	// resolved is the configuration the flags and config file add up to.
+	resolved *benchs.RunConfig
+}
+
+// suiteFlags selects the suites and configures their connections.
+func (o *runOptions) suiteFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.dialect, "dialect", "mysql", "database dialect: "+strings.Join(benchs.DialectNames(), ", "))
+	fs.IntVar(&benchs.ORM_MAX_IDLE, "max_idle", 200, "max idle conns")
+	fs.IntVar(&benchs.ORM_MAX_CONN, "max_conn", 200, "max open conns")
+	fs.StringVar(&benchs.ORM_SOURCE, "source", "", "dsn source, defaults to a local server of the chosen dialect")
+	fs.IntVar(&benchs.ORM_MULTI, "multi", 1, "base query nums x multi")
+	fs.Var(&o.orms, "orm", "orm name: all, "+strings.Join(benchs.BrandNames, ", "))
+	fs.StringVar(&o.bench, "bench", "", "only run benchmarks whose name matches this regular expression")
+	fs.Int64Var(&o.seed, "seed", 0, "seed of the random run order, 0 picks one, the seed used is printed")
+	fs.StringVar(&o.config, "config", "", "json file with run profiles, flags given on the command line take precedence")
+	fs.StringVar(&o.profile, "profile", "", "profile of -config to run, needed when it has more than one")
+}
+
+// timingFlags controls how long and how often the benchmarks run.
+func (o *runOptions) timingFlags(fs *flag.FlagSet) {
+	fs.Var(&benchs.ORM_BENCHTIME, "benchtime", "run each benchmark for a duration like 5s or a count like 5000x, default: suite's N; a duration applies to benchmarks using RunParallel only")
+	fs.Var(&benchs.ORM_WARMUP, "warmup", "untimed iterations before every benchmark using RunParallel, a duration like 1s or a count like 100x")
+	fs.DurationVar(&benchs.ORM_TIMEOUT, "timeout", 10*time.Minute, "abort a benchmark running longer than this, 0 disables")
+	fs.DurationVar(&o.totalTimeout, "total_timeout", 0, "abort the whole run after this and report what finished, 0 disables")
+	fs.IntVar(&benchs.ORM_COUNT, "count", 1, "run every benchmark count times")
+	fs.IntVar(&benchs.ORM_CONCURRENCY, "concurrency", 1, "goroutines sharing b.N of every benchmark")
+	fs.StringVar(&o.schedule, "schedule", "interleave", "run order: interleave runs every operation for all orms in random blocks, sequential one orm after the other")
+	fs.Float64Var(&benchs.ORM_OUTLIER, "outlier", 3.5, "modified z-score of ns/op, from the median and MAD of the -count samples, beyond which a sample is measured again, 0 disables")
+	fs.IntVar(&benchs.ORM_REMEASURE, "remeasure", 3, "measure at most this many outliers of a benchmark again, then mark it unstable")
+	fs.BoolVar(&benchs.ORM_CALIBRATE, "calibrate", true, "measure the harness overhead with an empty benchmark and subtract it from the results")
+}
+
+// applyConfig sets the flags the selected profile has values for, unless
+// they were given on the command line.
+func (o *runOptions) applyConfig(fs *flag.FlagSet) error {
+	c, err := benchs.ReadConfig(o.config)
+	if err != nil {
+		return err
+	}
+	name, p, err := c.Profile(o.profile)
+	if err != nil {
+		return fmt.Errorf("%s: %v", o.config, err)
+	}
+	o.profile = name
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	for flagName, values := range p.Flags() {
+		if set[flagName] || fs.Lookup(flagName) == nil {
+			continue
+		}
+		for _, v := range values {
+			if err := fs.Set(flagName, v); err != nil {
+				return fmt.Errorf("%s: profile %s: %s: %v", o.config, name, flagName, err)
+			}
+		}
+	}
+	benchs.ORM_OVERRIDES = p.Benchmarks
+	return nil
+}
+
+// resolve records the value of every flag after applying the config.
+func (o *runOptions) resolve(fs *flag.FlagSet) *benchs.RunConfig {
+	c := &benchs.RunConfig{
+		Config:     o.config,
+		Profile:    o.profile,
+		Flags:      make(map[string]string),
+		Benchmarks: benchs.ORM_OVERRIDES,
+	}
+	fs.VisitAll(func(f *flag.Flag) {
+		if f.Name != "config" && f.Name != "profile" {
+			c.Flags[f.Name] = f.Value.String()
+		}
+	})
+	c.Flags["source"] = benchs.CurrentDialect().RedactSource(benchs.ORM_SOURCE)
+	return c
+}
+
+// setup applies the parsed flags and the config file and returns the
+// suites to run.
+func (o *runOptions) setup(fs *flag.FlagSet) ListOpts {
+	if o.config != "" {
+		if err := o.applyConfig(fs); err != nil {
+			fmt.Println(err)
+			os.Exit(2)
+		}
+	}
+	if err := benchs.UseDialect(o.dialect); err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	if o.bench != "" {
+		re, err := regexp.Compile(o.bench)
+		if err != nil {
+			fmt.Println("invalid -bench:", err)
+			os.Exit(2)
+		}
+		benchs.ORM_BENCH = re
+	}
+	if o.totalTimeout > 0 {
+		benchs.ORM_DEADLINE = time.Now().Add(o.totalTimeout)
+	}
+	if benchs.ORM_SOURCE == "" {
+		benchs.ORM_SOURCE = benchs.CurrentDialect().DefaultSource
+	}
+
+	orms := o.orms
+	var all bool
+
+	if len(orms) == 0 {
+		all = true
+	} else {
+		for _, n := range orms {
+			if n == "all" {
+				all = true
+			}
+		}
+	}
+
+	if all {
+		orms = append(ListOpts(nil), benchs.BrandNames...)
+	}
+
+	if o.seed == 0 {
+		o.seed = time.Now().UnixNano()
+	}
+
+	if o.replay != "" {
+		m, err := benchs.ReadManifest(o.replay)
+		if err != nil {
+			fmt.Println("replay:", err)
+			os.Exit(2)
+		}
+		benchs.ORM_OVERRIDES = m.Overrides()
+		benchs.ORM_PINNED = m.Pinned()
+		o.plan = m
+	}
+
+	o.resolved = o.resolve(fs)
+	if o.plan != nil {
+		// the N calibrated by a duration is pinned instead
+		benchs.ORM_BENCHTIME.D = 0
+	}
+	return orms
+}
+
+// runSuites runs the benchmarks of orms in random order.
+func (o *runOptions) runSuites(orms ListOpts) {
+	if benchs.PrepareBenchmarks(orms) == 0 {
+		fmt.Printf("no benchmark matches -bench %q\n", o.bench)
+		os.Exit(2)
+	}
+	o.resumeCheckpoint()
+
+	rd := rand.New(rand.NewSource(o.seed))
+	orms.Shuffle(rd)
+	o.startPlan(orms)
+
+	if o.schedule == "interleave" {
+		benchs.RunInterleaved(orms, rd)
+		return
+	}
+	for _, n := range orms {
+		fmt.Println(n)
+		benchs.RunBenchmark(n)
+	}
+}
+
+// startPlan writes the manifest of the shuffled orms and, with -replay,
+// exits if they differ from the plan replayed.
+func (o *runOptions) startPlan(orms ListOpts) {
+	if o.manifest == "" && o.plan == nil {
+		return
+	}
+	m := benchs.NewManifest(o.resolved, o.seed, orms)
+	if o.plan != nil {
+		if err := o.plan.Check(m); err != nil {
+			fmt.Printf("replay %s: %v\n", o.replay, err)
+			os.Exit(2)
+		}
+	}
+	if o.manifest == "" {
+		return
+	}
+	if err := benchs.WriteManifest(o.manifest, m); err != nil {
+		fmt.Fprintln(os.Stderr, "save manifest:", err)
+	}
+	o.written = m
+}
+
+// recordRuns rewrites the manifest with the N each sample of results ran,
+// which -replay pins.
+func (o *runOptions) recordRuns(results *benchs.Results) {
+	if o.written == nil {
+		return
+	}
+	o.written.RecordRuns(results)
+	if err := benchs.WriteManifest(o.manifest, o.written); err != nil {
+		fmt.Fprintln(os.Stderr, "save manifest:", err)
+	}
+}
+
+// resumeCheckpoint loads the checkpoint of an interrupted run with -resume.
+func (o *runOptions) resumeCheckpoint() {
+	if !o.resume {
+		return
+	}
+	r, err := benchs.ReadResults(benchs.ORM_CHECKPOINT)
+	if err != nil {
+		fmt.Println("resume:", err)
+		os.Exit(2)
+	}
+	fmt.Printf("resuming %s: %d runs done\n\n", benchs.ORM_CHECKPOINT, r.Resume())
+}
+
+// trapSignals lets the first SIGINT or SIGTERM finish the running
+// benchmark and the second abort it, either way the results so far are
+// reported. The third exits at once.
+func trapSignals() {
+	c := make(chan os.Signal, 3)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		for sig := range c {
+			signalChild(sig)
+			switch benchs.Interrupt() {
+			case 1:
+				fmt.Fprintln(os.Stderr, "\ninterrupted: finishing the running benchmark, interrupt again to abort it")
+			case 2:
+				fmt.Fprintln(os.Stderr, "\ninterrupted: aborting the running benchmark, interrupt again to exit at once")
+			default:
+				os.Exit(130)
+			}
+		}
+	}()
+}
+
+func runCmd(c command, args []string) int {
+	var o runOptions
+	var out string
+	fs := newFlagSet(c)
+	o.suiteFlags(fs)
+	o.timingFlags(fs)
+	fs.StringVar(&out, "out", "results.json", "save the results to this file for report and compare, empty disables")
+	fs.StringVar(&o.isolate, "isolate", "", "run every suite or benchmark in its own process: suite, benchmark")
+	var budgetsFile string
+	fs.StringVar(&budgetsFile, "budgets", "", "file of budgets like \"gorm Insert allocs/op <= 120\" checked after the run, one failing exits non-zero")
+	var resultsFd int
+	fs.IntVar(&resultsFd, "results_fd", 0, "internal, used by -isolate: write the results to this file descriptor")
+	fs.StringVar(&benchs.ORM_CHECKPOINT, "checkpoint", "checkpoint.json", "save the results after every benchmark to this file, it's removed when the run completes, empty disables")
+	fs.BoolVar(&o.resume, "resume", false, "continue an interrupted run from -checkpoint, skipping what finished")
+	fs.StringVar(&o.replay, "replay", "", "internal, used by rerun: take N and L and the N each sample ran from this manifest and check the plan matches it")
+	fs.Parse(args)
+
+	orms := o.setup(fs)
+	trapSignals()
+	if o.schedule != "interleave" && o.schedule != "sequential" {
+		fmt.Printf("invalid -schedule %s, expected interleave or sequential\n", o.schedule)
+		return 2
+	}
+
+	if resultsFd > 0 {
+		// a child runs part of the plan, the parent checks all of it
+		o.plan = nil
+		o.runSuites(orms)
+		if err := writeChildResults(resultsFd); err != nil {
+			fmt.Fprintln(os.Stderr, "send results:", err)
+			return 2
+		}
+		if benchs.Failed() {
+			return 1
+		}
+		return 0
+	}
+
+	var budgets []*benchs.Budget
+	if budgetsFile != "" {
+		var err error
+		if budgets, err = benchs.ReadBudgets(budgetsFile); err != nil {
+			fmt.Println(err)
+			return 2
+		}
+	}
+
+	fmt.Println(o.resolved)
+	benchs.CaptureEnvironment()
+	o.manifest = benchs.ManifestPath(out)
+	switch o.isolate {
+	case "":
+		o.runSuites(orms)
+	case "suite", "benchmark":
+		o.runIsolated(fs, orms)
+	default:
+		fmt.Printf("invalid -isolate %s, expected suite or benchmark\n", o.isolate)
+		return 2
+	}
+
+	if benchs.Interrupted() {
+		fmt.Print("\nReports (interrupted, partial): \n\n")
+	} else {
+		fmt.Print("\nReports: \n\n")
+	}
+	fmt.Print(benchs.MakeReport())
+
+	results := benchs.CollectResults()
+	results.Config = o.resolved
+	o.recordRuns(results)
+	if out != "" {
+		if err := benchs.WriteResults(out, results); err != nil {
+			fmt.Fprintln(os.Stderr, "save results:", err)
+			return 1
+		}
+	}
+
+	budgetsPassed := true
+	if len(budgets) > 0 {
+		checked := benchs.CheckBudgets(budgets, results)
+		fmt.Print("\nBudgets: \n\n" + benchs.MakeBudgetReport(checked))
+		budgetsPassed = benchs.BudgetsPassed(checked)
+	}
+
+	if benchs.Interrupted() {
+		if benchs.ORM_CHECKPOINT != "" {
+			fmt.Fprintf(os.Stderr, "\ninterrupted, continue with the same flags and -resume, the results so far are in %s\n", benchs.ORM_CHECKPOINT)
+		}
+		return 130
+	}
+	if benchs.ORM_CHECKPOINT != "" {
+		os.Remove(benchs.ORM_CHECKPOINT)
+	}
+
+	if benchs.Failed() {
+		fmt.Fprintln(os.Stderr, "\nFAIL: some benchmarks failed, see the report above")
+		return 1
+	}
+	if !budgetsPassed {
+		fmt.Fprintln(os.Stderr, "\nFAIL: some budgets were exceeded, see the table above")
+		return 1
+	}
+	return 0
+}
+
+func listCmd(c command, args []string) int {
+	var o runOptions
+	fs := newFlagSet(c)
+	o.suiteFlags(fs)
+	fs.Parse(args)
+
+	fmt.Print(benchs.ListBenchmarks(o.setup(fs)))
+	return 0
+}
+
+func validateCmd(c command, args []string) int {
+	var o runOptions
+	fs := newFlagSet(c)
+	o.suiteFlags(fs)
+	fs.DurationVar(&benchs.ORM_TIMEOUT, "timeout", time.Minute, "abort a benchmark running longer than this, 0 disables")
+	fs.Parse(args)
+
+	orms := o.setup(fs)
+	benchs.ORM_BENCHTIME = benchs.BenchTime{N: 1}
+	benchs.ORM_COUNT = 1
+	benchs.ORM_CONCURRENCY = 1
+	o.runSuites(orms)
+
+	fmt.Print("\nCapabilities: \n\n")
+	fmt.Print(benchs.MakeCapabilities())
+
+	if benchs.Failed() {
+		fmt.Fprintln(os.Stderr, "\nFAIL: some benchmarks failed, see above")
+		return 1
+	}
+	return 0
+}
+
+func reportCmd(c command, args []string) int {
+	var format string
+	fs := newFlagSet(c)
+	fs.StringVar(&format, "format", "text", "report format: "+strings.Join(benchs.ReportFormats, ", "))
+	fs.Parse(args)
+
+	path := "results.json"
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return 2
+	} else if fs.NArg() == 1 {
+		path = fs.Arg(0)
+	}
+
+	results, err := benchs.ReadResults(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	results.Restore()
+	if format == "text" && results.Config != nil {
+		fmt.Println(results.Config)
+	}
+
+	report, err := benchs.RenderReport(format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	fmt.Print(report)
+	return 0
+}
+
+func compareCmd(c command, args []string) int {
+	fs := newFlagSet(c)
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+	old, err := benchs.ReadResults(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	cur, err := benchs.ReadResults(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	fmt.Print(benchs.Compare(old, cur))
+	return 0
+}
+
+func budgetCmd(c command, args []string) int {
+	fs := newFlagSet(c)
+	fs.Parse(args)
+
+	path := "results.json"
+	switch fs.NArg() {
+	case 1:
+	case 2:
+		path = fs.Arg(1)
+	default:
+		fs.Usage()
+		return 2
+	}
+	budgets, err := benchs.ReadBudgets(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	results, err := benchs.ReadResults(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	checked := benchs.CheckBudgets(budgets, results)
+	fmt.Print(benchs.MakeBudgetReport(checked))
+	if !benchs.BudgetsPassed(checked) {
+		return 1
+	}
+	return 0
+}
+
+// rerunCmd runs the plan of a manifest again: the flags, seed, suite order
+// and N and L of every benchmark are those of the manifest.
+func rerunCmd(c command, args []string) int {
+	var out, source string
+	fs := newFlagSet(c)
+	fs.StringVar(&out, "out", "", "save the results to this file, the manifest of the rerun goes next to it, default: results.rerun.json for results.manifest.json")
+	fs.StringVar(&source, "source", "", "dsn source, needed when the manifest's has a redacted password other than the default one")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+	path := fs.Arg(0)
+	if out == "" {
+		out = strings.TrimSuffix(strings.TrimSuffix(path, ".json"), ".manifest") + ".rerun.json"
+	}
+	if filepath.Clean(benchs.ManifestPath(out)) == filepath.Clean(path) {
+		fmt.Fprintf(os.Stderr, "-out %s would overwrite the manifest %s and the results it was written with\n", out, path)
+		return 2
+	}
+	m, err := benchs.ReadManifest(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if source, err = m.Unredact(source); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if m.Config == nil {
+		fmt.Fprintf(os.Stderr, "%s has no config\n", path)
+		return 2
+	}
+
+	runArgs := []string{"-source=" + source, "-out=" + out, "-replay=" + path}
+	var names []string
+	for name := range m.Config.Flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := m.Config.Flags[name]
+		switch name {
+		case "source", "out", "checkpoint", "resume", "results_fd", "replay":
+			continue
+		case "orm":
+			// printed by ListOpts.String as [a b]
+			for _, orm := range strings.Fields(strings.Trim(value, "[]")) {
+				runArgs = append(runArgs, "-orm="+orm)
+			}
+			continue
+		}
+		if value != "" {
+			runArgs = append(runArgs, "-"+name+"="+value)
+		}
+	}
+	fmt.Printf("rerun %s: run %s\n\n", path, strings.Join(runArgs[1:], " "))
+	return runCmd(command{name: "run"}, runArgs)
+}
+
+// Main runs the command given by os.Args and exits.
+func Main() {
+	runtime.GOMAXPROCS(runtime.NumCPU())
+
+	// without a command the flags are those of run, as before commands existed
+	args := os.Args[1:]
+	name := "run"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	if name == "help" {
+		usage()
+		return
+	}
+
+	for _, c := range commands {
+		if c.name == name {
+			os.Exit(c.run(c, args))
+		}
+	}
+	fmt.Fprintf(os.Stderr, "unknown command %s\n\n", name)
+	usage()
+	os.Exit(2)
+}
@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestListOptsSet(t *testing.T) {
+	var opts ListOpts
+	for _, v := range []string{"raw", "all", "gorm"} {
+		if err := opts.Set(v); err != nil {
+			t.Errorf("Set(%q) = %v", v, err)
+		}
+	}
+	if got := opts.String(); got != "[raw all gorm]" {
+		t.Errorf("String() = %s, want [raw all gorm]", got)
+	}
+
+	// a part of a name or of the list of names isn't one
+	for _, v := range []string{"", "nope", "ra", "raw gorm", "RAW"} {
+		if err := opts.Set(v); err == nil {
+			t.Errorf("Set(%q) succeeded", v)
+		}
+	}
+	if len(opts) != 3 {
+		t.Errorf("failed Set calls changed the list: %v", opts)
+	}
+}
+
+func TestListOptsShuffle(t *testing.T) {
+	opts := ListOpts{"a", "b", "c", "d", "e"}
+	again := append(ListOpts(nil), opts...)
+	opts.Shuffle(rand.New(rand.NewSource(42)))
+	again.Shuffle(rand.New(rand.NewSource(42)))
+	if opts.String() != again.String() {
+		t.Errorf("same seed shuffled to %v and %v", opts, again)
+	}
+
+	sorted := append(ListOpts(nil), opts...)
+	sort.Strings(sorted)
+	if got := sorted.String(); got != "[a b c d e]" {
+		t.Errorf("shuffle changed the elements: %v", opts)
+	}
+}
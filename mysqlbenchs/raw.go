@@ -1,234 +0,0 @@
-package benchs
-
-import (
-	"database/sql"
-	"fmt"
-	"strconv"
-	"strings"
-)
-
-var raw *sql.DB
-
-const (
-	rawInsertBaseSQL   = `INSERT INTO models (name, title, fax, web, age, counter) VALUES `
-	rawInsertValuesSQL = `(?, ?, ?, ?, ?, ?)`
-	rawInsertSQL       = rawInsertBaseSQL + rawInsertValuesSQL
-	rawUpdateSQL       = `UPDATE models SET name = ?, title = ?, fax = ?, web = ?, age = ?, counter = ? WHERE id = ?`
-	rawSelectSQL       = `SELECT id, name, title, fax, web, age,counter FROM models WHERE id = ?`
-	rawSelectMultiSQL  = `SELECT id, name, title, fax, web, age, counter FROM models WHERE id > 0 LIMIT 100`
-)
-
-func init() {
-	st := NewSuite("raw")
-	st.InitF = func() {
-		st.AddBenchmark("Insert", 2000*ORM_MULTI, 0, RawInsert)
-		st.AddBenchmark("BulkInsert 100 row", 2000*ORM_MULTI, 0, RawInsertMulti)
-		st.AddBenchmark("Update", 2000*ORM_MULTI, 0, RawUpdate)
-		st.AddBenchmark("Read", 2000*ORM_MULTI, 0, RawRead)
-		st.AddBenchmark("MultiRead limit 1000", 2000*ORM_MULTI, 1000, RawReadSlice)
-
-		raw, _ = sql.Open("mysql", ORM_SOURCE)
-	}
-}
-
-func RawInsert(b *B) {
-	var m *Model
-	var stmt *sql.Stmt
-	wrapExecute(b, func() {
-		var err error
-		initDB()
-		m = NewModel()
-		stmt, err = raw.Prepare(rawInsertSQL)
-		if err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	})
-	defer stmt.Close()
-
-	for i := 0; i < b.N; i++ {
-		// pq dose not support the LastInsertId method.
-		_, err := stmt.Exec(m.Name, m.Title, m.Fax, m.Web, m.Age, m.Counter)
-		if err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	}
-}
-
-func rawInsert(m *Model) error {
-	// pq dose not support the LastInsertId method.
-	_, err := raw.Exec(rawInsertSQL, m.Name, m.Title, m.Fax, m.Web, m.Age, m.Counter)
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-func RawInsertMulti(b *B) {
-	var ms []*Model
-	wrapExecute(b, func() {
-		initDB()
-
-		ms = make([]*Model, 0, 100)
-		for i := 0; i < 100; i++ {
-			ms = append(ms, NewModel())
-		}
-	})
-
-	var valuesSQL string
-	counter := 1
-	for i := 0; i < 100; i++ {
-		hoge := ""
-		for j := 0; j < 6; j++ {
-			if j != 5 {
-				hoge += "?" + ","
-			} else {
-				hoge += "?"
-			}
-			counter++
-		}
-		if i != 99 {
-			valuesSQL += "(" + hoge + "),"
-		} else {
-			valuesSQL += "(" + hoge + ")"
-		}
-	}
-
-	for i := 0; i < b.N; i++ {
-		nFields := 6
-		query := rawInsertBaseSQL + valuesSQL
-		args := make([]interface{}, len(ms)*nFields)
-		for j := range ms {
-			offset := j * nFields
-			args[offset+0] = ms[j].Name
-			args[offset+1] = ms[j].Title
-			args[offset+2] = ms[j].Fax
-			args[offset+3] = ms[j].Web
-			args[offset+4] = ms[j].Age
-			args[offset+5] = ms[j].Counter
-		}
-		// pq dose not support the LastInsertId method.
-		_, err := raw.Exec(query, args...)
-		if err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	}
-}
-
-func RawUpdate(b *B) {
-	var m *Model
-	var stmt *sql.Stmt
-	wrapExecute(b, func() {
-		var err error
-		initDB()
-		m = NewModel()
-		rawInsert(m)
-		stmt, err = raw.Prepare(rawUpdateSQL)
-		if err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	})
-	defer stmt.Close()
-
-	for i := 0; i < b.N; i++ {
-		_, err := stmt.Exec(m.Name, m.Title, m.Fax, m.Web, m.Age, m.Counter, m.Id)
-		if err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	}
-}
-
-func RawRead(b *B) {
-	var m *Model
-	var stmt *sql.Stmt
-	wrapExecute(b, func() {
-		var err error
-		initDB()
-		m = NewModel()
-		rawInsert(m)
-		stmt, err = raw.Prepare(rawSelectSQL)
-		if err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	})
-	defer stmt.Close()
-
-	for i := 0; i < b.N; i++ {
-		var mout Model
-		err := stmt.QueryRow(1).Scan(
-			&mout.Id,
-			&mout.Name,
-			&mout.Title,
-			&mout.Fax,
-			&mout.Web,
-			&mout.Age,
-			&mout.Counter,
-		)
-		if err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	}
-}
-
-func RawReadSlice(b *B) {
-	var m *Model
-	var stmt *sql.Stmt
-	wrapExecute(b, func() {
-		var err error
-		initDB()
-		m = NewModel()
-		for i := 0; i < b.L; i++ {
-			err = rawInsert(m)
-			if err != nil {
-				fmt.Println(err)
-				b.FailNow()
-			}
-		}
-		stmt, err = raw.Prepare(strings.Replace(rawSelectMultiSQL, "100", strconv.Itoa(b.L), -1))
-		if err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	})
-	defer stmt.Close()
-
-	for i := 0; i < b.N; i++ {
-		var j int
-		models := make([]Model, b.L)
-		rows, err := stmt.Query()
-		if err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-		for j = 0; rows.Next() && j < len(models); j++ {
-			err = rows.Scan(
-				&models[j].Id,
-				&models[j].Name,
-				&models[j].Title,
-				&models[j].Fax,
-				&models[j].Web,
-				&models[j].Age,
-				&models[j].Counter,
-			)
-			if err != nil {
-				fmt.Println(err)
-				b.FailNow()
-			}
-		}
-		models = models[:j]
-		if err = rows.Err(); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-		if err = rows.Close(); err != nil {
-			fmt.Println(err)
-			b.FailNow()
-		}
-	}
-}